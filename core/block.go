@@ -3,7 +3,7 @@ package core
 
 import (
 	"bytes"
-	"encoding/gob"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"time"
@@ -15,10 +15,18 @@ import (
 
 var (
 	BlockMissingSignature = errors.New("The verified block has no signature.")
+	BlockMissingHeader    = errors.New("The verified block has no header.")
+	BlockNilTransaction   = errors.New("The verified block contains a nil transaction.")
+	TxNotInBlock          = errors.New("The transaction is not included in the block.")
+	BlockTxCountMismatch  = errors.New("The block header transaction count does not match its transactions.")
+	BlockInvalidVersion   = errors.New("The block header version is not the protocol version.")
 )
 
 // PROTOCOL_VERSION represents the version of the Block format.
-const PROTOCOL_VERSION = 1
+const PROTOCOL_VERSION = 2
+
+// TX_COUNT_VERSION is the first Header Version committing to the TxCount.
+const TX_COUNT_VERSION = 2
 
 // A Header is storing a Block metadatas.
 type Header struct {
@@ -27,24 +35,49 @@ type Header struct {
 	PrevBlockHash crypto.Hash
 	Height        uint32
 	Timestamp     int64
+	TxCount       uint32 // Number of Transactions in the Block, part of the Header since TX_COUNT_VERSION
 }
 
 // Bytes returns the byte slice representation of the Header.
+// The fields are written in a fixed order using little-endian encoding so the
+// output (and thus the Block Hash) does not depend on the Go version or gob format.
+// The Version is written first so that future layouts can be told apart:
+// the TxCount is only written from TX_COUNT_VERSION on, so older Headers keep their Hash.
 func (h *Header) Bytes() []byte {
 	buf := &bytes.Buffer{}
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(h); err != nil {
+
+	if err := binary.Write(buf, binary.LittleEndian, h.Version); err != nil {
+		panic(err)
+	}
+
+	buf.Write(h.DataHash.Bytes())
+	buf.Write(h.PrevBlockHash.Bytes())
+
+	if err := binary.Write(buf, binary.LittleEndian, h.Height); err != nil {
 		panic(err)
 	}
 
+	if err := binary.Write(buf, binary.LittleEndian, h.Timestamp); err != nil {
+		panic(err)
+	}
+
+	if h.Version >= TX_COUNT_VERSION {
+		if err := binary.Write(buf, binary.LittleEndian, h.TxCount); err != nil {
+			panic(err)
+		}
+	}
+
 	return buf.Bytes()
 }
 
 // A Block contains a set of Transactions and the Signature of the Validator.
+// The SignerKey is only carried for a Scheme without PublicKey recovery.
 type Block struct {
 	*Header
 	Transactions []*Transaction
 	Signature    crypto.Signature
+	Scheme       crypto.SignatureScheme
+	SignerKey    crypto.PublicKey
 
 	headerHash crypto.Hash
 }
@@ -70,67 +103,86 @@ func NewBlockFromPrevHeader(prevHeader *Header, txx []*Transaction) (*Block, err
 		DataHash:      dataHash,
 		PrevBlockHash: BlockHasher{}.Hash(prevHeader),
 		Timestamp:     time.Now().UnixNano(),
+		TxCount:       uint32(len(txx)),
 	}
 
 	return NewBlock(header, txx)
 }
 
-// AddTx adds a single Transaction to the Block and recompute the DataHash.
-// This function invalidates the Block Hash cached.
+// AddTx adds a single Transaction to the Block and recompute the DataHash and TxCount.
+// This function invalidates the Block Hash cached. The Block is left untouched on error.
 func (b *Block) AddTx(tx *Transaction) error {
-	b.Transactions = append(b.Transactions, tx)
-	hash, err := ComputeDataHash(b.Transactions)
-	if err != nil {
-		return err
-	}
-
-	b.DataHash = hash
-	b.InvalidateHeaderHash()
-	return nil
+	return b.AddTxx([]*Transaction{tx})
 }
 
-// AddTx adds multiple Transactions to the Block and recompute the DataHash.
-// This function invalidates the Block Hash cached.
+// AddTx adds multiple Transactions to the Block and recompute the DataHash and TxCount.
+// This function invalidates the Block Hash cached. The Block is left untouched on error.
 func (b *Block) AddTxx(txx []*Transaction) error {
-	b.Transactions = append(b.Transactions, txx...)
-	hash, err := ComputeDataHash(b.Transactions)
+	n := len(b.Transactions)
+	transactions := append(b.Transactions[:n:n], txx...)
+	hash, err := ComputeDataHash(transactions)
 	if err != nil {
 		return err
 	}
 
+	b.Transactions = transactions
 	b.DataHash = hash
+	b.TxCount = uint32(len(b.Transactions))
 	b.InvalidateHeaderHash()
 	return nil
 }
 
 // Sign computes the signature of the HeaderHash which certifies the content of the Block.
-func (b *Block) Sign(privKey crypto.PrivateKey) error {
+// The Signer PublicKey is carried as the SignerKey if its scheme has no PublicKey recovery.
+func (b *Block) Sign(signer crypto.Signer) error {
 	headerHash := b.HeaderHash(BlockHasher{})
-	sig, err := privKey.Sign(headerHash)
+	sig, err := signer.Sign(headerHash)
 	if err != nil {
 		return err
 	}
 
 	b.Signature = sig
+	b.Scheme = signer.Scheme()
+	b.SignerKey = nil
+	if !b.Scheme.Recoverable() {
+		b.SignerKey = signer.PublicKey()
+	}
 
 	return nil
 }
 
-// VerifyData checks that the Block Transactions hash is matching the Header DataHash.
+// VerifyData checks that the Header Version is the PROTOCOL_VERSION and that its TxCount
+// and DataHash are matching the Block Transactions count and recomputed Merkle root.
 func (b *Block) VerifyData() error {
-	if b.Signature == nil {
+	if b.Header == nil {
+		return BlockMissingHeader
+	}
+
+	if b.Version != PROTOCOL_VERSION {
+		return fmt.Errorf("Block version %d, expected %d: %w", b.Version, PROTOCOL_VERSION, BlockInvalidVersion)
+	}
+
+	if len(b.Signature) == 0 {
 		return BlockMissingSignature
 	}
 
 	headerHash := b.HeaderHash(BlockHasher{})
 
 	for _, tx := range b.Transactions {
+		if tx == nil {
+			return BlockNilTransaction
+		}
+
 		_, err := tx.Signer()
 		if err != nil {
 			return err
 		}
 	}
 
+	if int(b.TxCount) != len(b.Transactions) {
+		return fmt.Errorf("Block [%s] tx count %d, holds %d: %w", headerHash.String(), b.TxCount, len(b.Transactions), BlockTxCountMismatch)
+	}
+
 	computedDataHash, err := ComputeDataHash(b.Transactions)
 	if err != nil {
 		return err
@@ -143,22 +195,88 @@ func (b *Block) VerifyData() error {
 	return nil
 }
 
+// VerifyChainID checks that all the Block Transactions were signed for the chainID.
+func (b *Block) VerifyChainID(chainID uint32) error {
+	for _, tx := range b.Transactions {
+		if tx == nil {
+			return BlockNilTransaction
+		}
+
+		if tx.ChainID != chainID {
+			return fmt.Errorf("Tx [%s] chain ID %d: %w", tx.Hash(TxHasher{}).String(), tx.ChainID, TxChainIDMismatch)
+		}
+	}
+
+	return nil
+}
+
+// InclusionProof returns the Block Transaction matching the txHash along with the
+// MerkleProof of its inclusion in the Block DataHash.
+func (b *Block) InclusionProof(txHash crypto.Hash) (*Transaction, *MerkleProof, error) {
+	index := -1
+	for i, tx := range b.Transactions {
+		if tx != nil && tx.Hash(TxHasher{}) == txHash {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 {
+		return nil, nil, fmt.Errorf("Tx [%s]: %w", txHash.String(), TxNotInBlock)
+	}
+
+	leaves, err := computeLeaves(b.Transactions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof, err := ComputeMerkleProof(leaves, index)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return b.Transactions[index], proof, nil
+}
+
 // Signer returns the PublicKey of the Block Signature signer.
 func (b *Block) Signer() (crypto.PublicKey, error) {
-	if b.Signature == nil {
+	if b.Header == nil {
+		return nil, BlockMissingHeader
+	}
+
+	if len(b.Signature) == 0 {
 		return nil, BlockMissingSignature
 	}
 
 	headerHash := b.HeaderHash(BlockHasher{})
 
-	sigPubKey, err := b.Signature.PublicKey(headerHash)
+	sigPubKey, err := crypto.SignerPublicKey(b.Scheme, b.SignerKey, headerHash, b.Signature)
 	if err != nil {
-		return nil, fmt.Errorf("Block [%s] header signature public key recovery failed.", headerHash.String())
+		return nil, fmt.Errorf("Block [%s] header signature verification failed.", headerHash.String())
 	}
 
 	return sigPubKey, nil
 }
 
+// SignerAddress returns the Address of the Block Signature signer.
+func (b *Block) SignerAddress() (crypto.Address, error) {
+	if b.Header == nil {
+		return crypto.Address{}, BlockMissingHeader
+	}
+
+	if len(b.Signature) == 0 {
+		return crypto.Address{}, BlockMissingSignature
+	}
+
+	headerHash := b.HeaderHash(BlockHasher{})
+	address, err := crypto.SignerAddress(b.Scheme, b.SignerKey, headerHash, b.Signature)
+	if err != nil {
+		return crypto.Address{}, fmt.Errorf("Block [%s] header signature verification failed.", headerHash.String())
+	}
+
+	return address, nil
+}
+
 // Decode the Decoder into the Block.
 func (b *Block) Decode(dec Decoder[*Block]) error {
 	return dec.Decode(b)
@@ -184,16 +302,46 @@ func (b *Block) InvalidateHeaderHash() {
 	b.headerHash = crypto.Hash{}
 }
 
-// ComputeDataHash computes the Hash of all the Block Transactions.
+// ContentID returns a Hash identifying the Block content computed over the Height,
+// the PrevBlockHash and the DataHash only. Unlike HeaderHash it ignores volatile
+// fields such as the Timestamp so equivalent Blocks share the same ContentID.
+func (b *Block) ContentID() crypto.Hash {
+	buf := new(bytes.Buffer)
+
+	if err := binary.Write(buf, binary.LittleEndian, b.Height); err != nil {
+		panic(err)
+	}
+
+	buf.Write(b.PrevBlockHash.Bytes())
+	buf.Write(b.DataHash.Bytes())
+
+	return crypto.Hash(blake2b.Sum256(buf.Bytes()))
+}
+
+// ComputeDataHash computes the Merkle root of all the Block Transactions.
+// The Transactions are streamed into a single hasher to compute the leaves
+// so the memory used does not grow with the size of the Transactions.
+// It returns BlockNilTransaction if any Transaction is nil.
 func ComputeDataHash(txx []*Transaction) (crypto.Hash, error) {
-	buf := &bytes.Buffer{}
+	leaves, err := computeLeaves(txx)
+	if err != nil {
+		return crypto.Hash{}, err
+	}
+
+	return ComputeMerkleRoot(leaves), nil
+}
 
-	for _, tx := range txx {
-		if err := tx.Encode(NewGobTxEncoder(buf)); err != nil {
-			return crypto.Hash{}, err
+// computeLeaves returns the Merkle tree leaf Hashes of the Transactions
+// or BlockNilTransaction if any Transaction is nil.
+func computeLeaves(txx []*Transaction) ([]crypto.Hash, error) {
+	lh := newLeafHasher()
+	leaves := make([]crypto.Hash, len(txx))
+	for i, tx := range txx {
+		if tx == nil {
+			return nil, BlockNilTransaction
 		}
+		leaves[i] = lh.Hash(tx)
 	}
 
-	hash := blake2b.Sum256(buf.Bytes())
-	return hash, nil
+	return leaves, nil
 }
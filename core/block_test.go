@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/hex"
 	"testing"
 	"time"
 
@@ -9,6 +10,42 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+const (
+	HEADER_GOLDEN_BYTES = "01000000d02b1c9fe0516a37c2497e2403c0320d502f87346aed2868d9b700402809b15a" +
+		"c02b1c9fe0516a37c2487e2403c0320d502f87346aed2868d9b700402809b15a2a00000000802042a87a2917"
+	HEADER_GOLDEN_HASH     = "58b65f9bf1e416ab493499b8f20530e908ff445dbb8f390f2d070a87fb4aaa54"
+	HEADER_V2_GOLDEN_BYTES = "02000000d02b1c9fe0516a37c2497e2403c0320d502f87346aed2868d9b700402809b15a" +
+		"c02b1c9fe0516a37c2487e2403c0320d502f87346aed2868d9b700402809b15a2a00000000802042a87a291703000000"
+	HEADER_V2_GOLDEN_HASH = "066c8aec2d7704e1e543dfcbb7798069a1c4abbf08eac98dffc28b48fcc8905d"
+)
+
+func TestHeaderBytesGolden(t *testing.T) {
+	dataHash, err := crypto.HashFromString("d02b1c9fe0516a37c2497e2403c0320d502f87346aed2868d9b700402809b15a")
+	assert.Nil(t, err)
+	prevBlockHash, err := crypto.HashFromString("c02b1c9fe0516a37c2487e2403c0320d502f87346aed2868d9b700402809b15a")
+	assert.Nil(t, err)
+
+	header := &Header{
+		Version:       1,
+		DataHash:      dataHash,
+		PrevBlockHash: prevBlockHash,
+		Height:        42,
+		Timestamp:     1669000000000000000,
+		TxCount:       3,
+	}
+
+	// Check that the Header serialization and Hash match the pinned values.
+	// A failure here means every Block Hash changed and consensus would break.
+	// The version 1 layout ignores the TxCount.
+	assert.Equal(t, HEADER_GOLDEN_BYTES, hex.EncodeToString(header.Bytes()))
+	assert.Equal(t, HEADER_GOLDEN_HASH, BlockHasher{}.Hash(header).String())
+
+	// Check that the version 2 layout commits to the TxCount.
+	header.Version = 2
+	assert.Equal(t, HEADER_V2_GOLDEN_BYTES, hex.EncodeToString(header.Bytes()))
+	assert.Equal(t, HEADER_V2_GOLDEN_HASH, BlockHasher{}.Hash(header).String())
+}
+
 func TestBlockSign(t *testing.T) {
 	privKey, err := crypto.GeneratePrivateKey()
 	assert.Nil(t, err)
@@ -51,6 +88,87 @@ func TestBlockAddTx(t *testing.T) {
 	assert.Equal(t, privKey.PublicKey().Address().String(), blockSignerPublicKey.Address().String())
 }
 
+func TestBlockVerifyDataReordered(t *testing.T) {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+
+	// Add multiple signed Tx to the Block and sign it.
+	multipleTx := []*Transaction{genTxWithoutSignature(t), genTxWithoutSignature(t)}
+	assert.Nil(t, multipleTx[0].Sign(privKey))
+	assert.Nil(t, multipleTx[1].Sign(privKey))
+	assert.Nil(t, b.AddTxx(multipleTx))
+	assert.Nil(t, b.Sign(privKey))
+	assert.Nil(t, b.VerifyData())
+
+	// Swap the Transactions without updating the Header DataHash.
+	b.Transactions[0], b.Transactions[1] = b.Transactions[1], b.Transactions[0]
+
+	// Check that the recomputed Merkle root does not match the stale DataHash.
+	assert.NotNil(t, b.VerifyData())
+}
+
+func TestBlockVerifyChainID(t *testing.T) {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	// Add a Tx signed for chain 1 to the Block.
+	tx := NewTransaction([]byte("foo"), privKey.PublicKey().Address(), 42, 1, 0, 1)
+	assert.Nil(t, tx.Sign(privKey))
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTx(tx))
+
+	// Check that the Block is accepted on chain 1 and rejected on chain 2.
+	assert.Nil(t, b.VerifyChainID(1))
+	assert.ErrorIs(t, b.VerifyChainID(2), TxChainIDMismatch)
+
+	// Replay the Tx on chain 2 and check that the Signature no longer matches the sender.
+	tx.ChainID = 2
+	tx.InvalidateHash()
+	txSigner, err := tx.Signer()
+	assert.Nil(t, err)
+	assert.NotEqual(t, privKey.PublicKey().Address(), txSigner.Address())
+}
+
+func TestBlockDecodeMissingSignatures(t *testing.T) {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	// Add an unsigned Tx to a signed Block.
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTx(genTxWithoutSignature(t)))
+	assert.Nil(t, b.Sign(privKey))
+
+	// Encode and decode the Block.
+	blockEncoded := &bytes.Buffer{}
+	assert.Nil(t, b.Encode(NewGobBlockEncoder(blockEncoded)))
+	blockDecoded := new(Block)
+	assert.Nil(t, blockDecoded.Decode(NewGobBlockDecoder(blockEncoded)))
+
+	// Check that the decoded Tx without Signature is cleanly rejected.
+	assert.Nil(t, blockDecoded.Transactions[0].Signature)
+	assert.Equal(t, TxMissingSignature, blockDecoded.VerifyData())
+
+	// Check that an empty Signature is handled as a missing one.
+	blockDecoded.Transactions[0].Signature = crypto.Signature{}
+	assert.Equal(t, TxMissingSignature, blockDecoded.VerifyData())
+
+	// Check that a nil Tx is rejected without panic.
+	blockDecoded.Transactions[0] = nil
+	assert.Equal(t, BlockNilTransaction, blockDecoded.VerifyData())
+
+	// Check that a decoded Block without Header is rejected without panic.
+	headerless := &Block{Signature: b.Signature}
+	blockEncoded.Reset()
+	assert.Nil(t, headerless.Encode(NewGobBlockEncoder(blockEncoded)))
+	blockDecoded = new(Block)
+	assert.Nil(t, blockDecoded.Decode(NewGobBlockDecoder(blockEncoded)))
+	assert.Equal(t, BlockMissingHeader, blockDecoded.VerifyData())
+	_, err = blockDecoded.Signer()
+	assert.Equal(t, BlockMissingHeader, err)
+}
+
 func TestBlockDecodeEncode(t *testing.T) {
 	privKey, err := crypto.GeneratePrivateKey()
 	assert.Nil(t, err)
@@ -87,9 +205,146 @@ func TestBlockDecodeEncode(t *testing.T) {
 	assert.Equal(t, b.Signature, blockDecoded.Signature)
 }
 
+func TestBlockAddNilTransaction(t *testing.T) {
+	// Check that a nil Tx is rejected by the DataHash computation without panic.
+	_, err := ComputeDataHash([]*Transaction{nil})
+	assert.Equal(t, BlockNilTransaction, err)
+	_, err = NewBlockFromPrevHeader(&Header{}, []*Transaction{nil})
+	assert.Equal(t, BlockNilTransaction, err)
+
+	// Check that adding a nil Tx leaves the Block untouched.
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTx(genTxWithoutSignature(t)))
+	dataHash := b.DataHash
+	assert.Equal(t, BlockNilTransaction, b.AddTx(nil))
+	assert.Equal(t, BlockNilTransaction, b.AddTxx([]*Transaction{genTxWithoutSignature(t), nil}))
+	assert.Len(t, b.Transactions, 1)
+	assert.Equal(t, uint32(1), b.TxCount)
+	assert.Equal(t, dataHash, b.DataHash)
+}
+
+func TestBlockVerifyTxCount(t *testing.T) {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	// Create a Block from signed Tx and check that its TxCount is set.
+	multipleTx := []*Transaction{genTxWithoutSignature(t), genTxWithoutSignature(t)}
+	assert.Nil(t, multipleTx[0].Sign(privKey))
+	assert.Nil(t, multipleTx[1].Sign(privKey))
+	prev := randomBlockWithoutSignature(t, 0, crypto.Hash{})
+	b, err := NewBlockFromPrevHeader(prev.Header, multipleTx[:1])
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), b.TxCount)
+	assert.Nil(t, b.AddTx(multipleTx[1]))
+	assert.Equal(t, uint32(2), b.TxCount)
+	assert.Nil(t, b.Sign(privKey))
+	assert.Nil(t, b.VerifyData())
+
+	// Drop a Tx keeping a consistent DataHash but a stale TxCount.
+	b.Transactions = b.Transactions[:1]
+	b.DataHash, err = ComputeDataHash(b.Transactions)
+	assert.Nil(t, err)
+	b.InvalidateHeaderHash()
+	assert.Nil(t, b.Sign(privKey))
+
+	// Check that the mismatched TxCount is rejected.
+	assert.ErrorIs(t, b.VerifyData(), BlockTxCountMismatch)
+
+	// Check that NewBlock keeps the TxCount of a received Header so the mismatch is still caught.
+	received := *b.Header
+	received.TxCount = 5
+	b, err = NewBlock(&received, b.Transactions)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(5), b.TxCount)
+	assert.Nil(t, b.Sign(privKey))
+	assert.ErrorIs(t, b.VerifyData(), BlockTxCountMismatch)
+
+	// Check that a version 1 Header can not be used to skip the TxCount check.
+	received.Version = 1
+	b.InvalidateHeaderHash()
+	assert.Nil(t, b.Sign(privKey))
+	assert.ErrorIs(t, b.VerifyData(), BlockInvalidVersion)
+}
+
+func TestBlockJSONDecodeEncode(t *testing.T) {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	// Build a signed Block with signed Tx.
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{0x42})
+	multipleTx := []*Transaction{genTxWithoutSignature(t), genTxWithoutSignature(t)}
+	assert.Nil(t, multipleTx[0].Sign(privKey))
+	assert.Nil(t, multipleTx[1].Sign(privKey))
+	assert.Nil(t, b.AddTxx(multipleTx))
+	assert.Nil(t, b.Sign(privKey))
+
+	// Encode the Block and check that the Hashes are hexadecimal strings.
+	blockEncoded := &bytes.Buffer{}
+	assert.Nil(t, b.Encode(NewJSONBlockEncoder(blockEncoded)))
+	assert.Contains(t, blockEncoded.String(), `"`+b.PrevBlockHash.String()+`"`)
+	assert.Contains(t, blockEncoded.String(), `"`+b.Signature.String()+`"`)
+
+	// Decode the encoded Block and compare it with the original Block.
+	blockDecoded := new(Block)
+	assert.Nil(t, blockDecoded.Decode(NewJSONBlockDecoder(blockEncoded)))
+	assert.Equal(t, b.Header, blockDecoded.Header)
+	assert.Equal(t, b.Signature, blockDecoded.Signature)
+	for i := 0; i < len(b.Transactions); i++ {
+		b.Transactions[i].hash = crypto.Hash{}
+		assert.Equal(t, b.Transactions[i], blockDecoded.Transactions[i])
+	}
+
+	// Check that the decoded Block is still valid.
+	assert.Nil(t, blockDecoded.VerifyData())
+}
+
+func TestBlockContentID(t *testing.T) {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	tx := genTxWithoutSignature(t)
+	assert.Nil(t, tx.Sign(privKey))
+
+	// Build two Blocks with the same parent and Transactions but different Timestamps.
+	b1 := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b1.AddTx(tx))
+	b2 := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	b2.Timestamp = b1.Timestamp + 1
+	assert.Nil(t, b2.AddTx(tx))
+
+	// Check that the Blocks share a ContentID but not a HeaderHash.
+	assert.Equal(t, b1.ContentID(), b2.ContentID())
+	assert.NotEqual(t, b1.HeaderHash(BlockHasher{}), b2.HeaderHash(BlockHasher{}))
+
+	// Check that a Block at another height has a different ContentID.
+	b3 := randomBlockWithoutSignature(t, 2, crypto.Hash{})
+	assert.Nil(t, b3.AddTx(tx))
+	assert.NotEqual(t, b1.ContentID(), b3.ContentID())
+}
+
+func BenchmarkComputeDataHash(b *testing.B) {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(b, err)
+
+	// Generate 1000 signed Transactions.
+	txx := make([]*Transaction, 1000)
+	for i := range txx {
+		txx[i] = NewTransaction([]byte("foo"), privKey.PublicKey().Address(), uint64(i), 1, uint64(i), 0)
+		assert.Nil(b, txx[i].Sign(privKey))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	// Benchmark the DataHash computation.
+	for i := 0; i < b.N; i++ {
+		_, _ = ComputeDataHash(txx)
+	}
+}
+
 func randomBlockWithoutSignature(t *testing.T, height uint32, prevBlockHash crypto.Hash) *Block {
 	header := &Header{
-		Version:       1,
+		Version:       PROTOCOL_VERSION,
 		PrevBlockHash: prevBlockHash,
 		Height:        height,
 		Timestamp:     time.Now().UnixNano(),
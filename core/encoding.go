@@ -2,6 +2,7 @@ package core
 
 import (
 	"encoding/gob"
+	"encoding/json"
 	"io"
 )
 
@@ -82,3 +83,73 @@ func NewGobBlockDecoder(r io.Reader) *GobBlockDecoder {
 func (dec *GobBlockDecoder) Decode(b *Block) error {
 	return gob.NewDecoder(dec.r).Decode(b)
 }
+
+// JSONTxEncoder implements Encoder for Transaction using encoding/json.
+// The hashes, addresses, keys and signatures are encoded as hexadecimal strings.
+type JSONTxEncoder struct {
+	w io.Writer
+}
+
+// NewJSONTxEncoder returns a pointer to a JSONTxEncoder given an io.Writer.
+func NewJSONTxEncoder(w io.Writer) *JSONTxEncoder {
+	return &JSONTxEncoder{
+		w: w,
+	}
+}
+
+// Encode writes the JSON encoding of Transaction in the io.Writer w.
+func (e *JSONTxEncoder) Encode(tx *Transaction) error {
+	return json.NewEncoder(e.w).Encode(tx)
+}
+
+// JSONTxDecoder implements Decoder for Transaction using encoding/json.
+type JSONTxDecoder struct {
+	r io.Reader
+}
+
+// NewJSONTxDecoder returns a pointer to a JSONTxDecoder given an io.Reader.
+func NewJSONTxDecoder(r io.Reader) *JSONTxDecoder {
+	return &JSONTxDecoder{
+		r: r,
+	}
+}
+
+// Decode reads the JSON encoding in io.Reader r in Transaction tx.
+func (e *JSONTxDecoder) Decode(tx *Transaction) error {
+	return json.NewDecoder(e.r).Decode(tx)
+}
+
+// JSONBlockEncoder implements Encoder for Block using encoding/json.
+// The hashes, addresses, keys and signatures are encoded as hexadecimal strings.
+type JSONBlockEncoder struct {
+	w io.Writer
+}
+
+// NewJSONBlockEncoder returns a pointer to a JSONBlockEncoder given an io.Writer.
+func NewJSONBlockEncoder(w io.Writer) *JSONBlockEncoder {
+	return &JSONBlockEncoder{
+		w: w,
+	}
+}
+
+// Encode writes the JSON encoding of Block b in the io.Writer w.
+func (enc *JSONBlockEncoder) Encode(b *Block) error {
+	return json.NewEncoder(enc.w).Encode(b)
+}
+
+// JSONBlockDecoder implements Decoder for Block using encoding/json.
+type JSONBlockDecoder struct {
+	r io.Reader
+}
+
+// NewJSONBlockDecoder returns a pointer to a JSONBlockDecoder given an io.Reader.
+func NewJSONBlockDecoder(r io.Reader) *JSONBlockDecoder {
+	return &JSONBlockDecoder{
+		r: r,
+	}
+}
+
+// Decode reads the JSON encoding in io.Reader r in Block b.
+func (dec *JSONBlockDecoder) Decode(b *Block) error {
+	return json.NewDecoder(dec.r).Decode(b)
+}
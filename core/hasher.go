@@ -29,6 +29,10 @@ type TxHasher struct{}
 func (TxHasher) Hash(tx *Transaction) crypto.Hash {
 	buf := new(bytes.Buffer)
 
+	if err := binary.Write(buf, binary.LittleEndian, tx.ChainID); err != nil {
+		panic(err)
+	}
+
 	if err := binary.Write(buf, binary.LittleEndian, tx.To); err != nil {
 		panic(err)
 	}
@@ -37,6 +41,10 @@ func (TxHasher) Hash(tx *Transaction) crypto.Hash {
 		panic(err)
 	}
 
+	if err := binary.Write(buf, binary.LittleEndian, tx.Fee); err != nil {
+		panic(err)
+	}
+
 	if err := binary.Write(buf, binary.LittleEndian, tx.Nonce); err != nil {
 		panic(err)
 	}
@@ -1,16 +1,29 @@
 package core
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"math/bits"
+	"sort"
 	"sync"
 
+	"golang.org/x/crypto/blake2b"
+
 	"github.com/pacokleitz/ambula/crypto"
 )
 
+var (
+	BalanceOverflow = errors.New("The operation overflows an Account balance.")
+)
+
 // An Account is an entry in the LedgerState.
+// The Nonce is the number of Transactions sent by the Account, it is
+// the Nonce expected for the next Transaction sent by the Account.
 type Account struct {
 	Address crypto.Address
 	Balance uint64
+	Nonce   uint64
 }
 
 // The LedgerState is the datastructure storing and managing all Accounts.
@@ -26,6 +39,45 @@ func NewLedgerState() *LedgerState {
 	}
 }
 
+// NewFundedLedger initializes a LedgerState with an Account for each Address
+// of the allocations funded with the matching Balance.
+func NewFundedLedger(allocations map[crypto.Address]uint64) *LedgerState {
+	ls := NewLedgerState()
+	for address, balance := range allocations {
+		ls.accounts[address] = &Account{Address: address, Balance: balance}
+	}
+
+	return ls
+}
+
+// NewFundedLedgerWithKeys derives n PrivateKeys from the seed and returns them along
+// with a LedgerState where each matching Account is funded with balance.
+// The same seed always gives the same PrivateKeys and thus the same Accounts.
+func NewFundedLedgerWithKeys(seed []byte, n int, balance uint64) ([]crypto.PrivateKey, *LedgerState, error) {
+	entropy, err := blake2b.NewXOF(blake2b.OutputLengthUnknown, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := entropy.Write(seed); err != nil {
+		return nil, nil, err
+	}
+
+	privKeys := make([]crypto.PrivateKey, n)
+	allocations := make(map[crypto.Address]uint64, n)
+
+	for i := 0; i < n; i++ {
+		privKey, err := crypto.NewPrivateKeyFromReader(entropy)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		privKeys[i] = privKey
+		allocations[privKey.PublicKey().Address()] = balance
+	}
+
+	return privKeys, NewFundedLedger(allocations), nil
+}
+
 // CreateAccount create a new Account in the LedgerState from an Address.
 func (ls *LedgerState) CreateAccount(address crypto.Address) *Account {
 	ls.lock.Lock()
@@ -54,6 +106,32 @@ func (s *LedgerState) getAccountWithoutLock(address crypto.Address) (*Account, e
 	return acc, nil
 }
 
+// Accounts returns a copy of all the Accounts in the LedgerState sorted by Address.
+func (ls *LedgerState) Accounts() []*Account {
+	ls.lock.RLock()
+	defer ls.lock.RUnlock()
+
+	accounts := make([]*Account, 0, len(ls.accounts))
+	for _, acc := range ls.accounts {
+		accCopy := *acc
+		accounts = append(accounts, &accCopy)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return bytes.Compare(accounts[i].Address[:], accounts[j].Address[:]) < 0
+	})
+
+	return accounts
+}
+
+// Len returns the number of Accounts in the LedgerState.
+func (ls *LedgerState) Len() int {
+	ls.lock.RLock()
+	defer ls.lock.RUnlock()
+
+	return len(ls.accounts)
+}
+
 // GetBalance returns the Balance in the LedgerState for an Address.
 func (ls *LedgerState) GetBalance(address crypto.Address) (uint64, error) {
 	ls.lock.RLock()
@@ -67,6 +145,19 @@ func (ls *LedgerState) GetBalance(address crypto.Address) (uint64, error) {
 	return acc.Balance, nil
 }
 
+// GetNonce returns the Nonce in the LedgerState for an Address.
+func (ls *LedgerState) GetNonce(address crypto.Address) (uint64, error) {
+	ls.lock.RLock()
+	defer ls.lock.RUnlock()
+
+	acc, err := ls.getAccountWithoutLock(address)
+	if err != nil {
+		return 0, err
+	}
+
+	return acc.Nonce, nil
+}
+
 // Transfer transfers a funds amount from one Address to another.
 func (ls *LedgerState) Transfer(from, to crypto.Address, amount uint64) error {
 	ls.lock.Lock()
@@ -96,3 +187,207 @@ func (ls *LedgerState) Transfer(from, to crypto.Address, amount uint64) error {
 
 	return nil
 }
+
+// A TransferOp is a single transfer of a funds Amount applied by TransferBatch.
+type TransferOp struct {
+	From   crypto.Address
+	To     crypto.Address
+	Amount uint64
+}
+
+// TransferBatch applies the TransferOps in order as a single atomic operation.
+// Each transfer follows the Transfer rules (existing sender with sufficient funds)
+// and can spend funds received earlier in the batch.
+// If any TransferOp can not be applied the LedgerState is left untouched.
+func (ls *LedgerState) TransferBatch(ops []TransferOp) error {
+	ls.lock.Lock()
+	defer ls.lock.Unlock()
+
+	stage := newLedgerStage(ls)
+	for i, op := range ops {
+		if _, ok := stage.balances[op.From]; !ok {
+			if _, err := ls.getAccountWithoutLock(op.From); err != nil {
+				return fmt.Errorf("Transfer %d: %w", i, err)
+			}
+		}
+
+		if !stage.debit(op.From, op.Amount) {
+			return fmt.Errorf("Transfer %d: Account %s does not have sufficient funds for transfer.", i, op.From.String())
+		}
+
+		if err := stage.credit(op.To, op.Amount); err != nil {
+			return fmt.Errorf("Transfer %d: %w", i, err)
+		}
+	}
+
+	stage.commit()
+
+	return nil
+}
+
+// ApplyBlock applies the Block Transactions to the LedgerState in order.
+// Each Transaction sender is debited of the Transaction Value plus Fee and
+// the Block signer is credited of the sum of the Fees plus the block reward.
+// A Transaction Nonce must match its sender Account Nonce which is then incremented.
+// The Block data must match its Header and the Transactions must have been signed for the chainID.
+// If any Transaction can not be applied the LedgerState is left untouched.
+func (ls *LedgerState) ApplyBlock(b *Block, chainID uint32, reward uint64) error {
+	if err := b.VerifyData(); err != nil {
+		return err
+	}
+
+	producer, err := b.SignerAddress()
+	if err != nil {
+		return err
+	}
+
+	if err := b.VerifyChainID(chainID); err != nil {
+		return err
+	}
+
+	ls.lock.Lock()
+	defer ls.lock.Unlock()
+
+	stage := newLedgerStage(ls)
+	fees := reward
+	for _, tx := range b.Transactions {
+		if err := stage.applyTx(tx); err != nil {
+			return err
+		}
+
+		if fees, err = addBalance(fees, tx.Fee); err != nil {
+			return err
+		}
+	}
+
+	if err := stage.credit(producer, fees); err != nil {
+		return err
+	}
+
+	stage.commit()
+
+	return nil
+}
+
+// A ledgerStage stages Account balances and nonces on top of a LedgerState
+// so that a set of changes can be committed at once or dropped.
+// The LedgerState lock must be held while the ledgerStage is in use.
+type ledgerStage struct {
+	ls       *LedgerState
+	balances map[crypto.Address]uint64
+	nonces   map[crypto.Address]uint64
+}
+
+// newLedgerStage initializes an empty ledgerStage on top of the LedgerState.
+func newLedgerStage(ls *LedgerState) *ledgerStage {
+	return &ledgerStage{
+		ls:       ls,
+		balances: make(map[crypto.Address]uint64),
+		nonces:   make(map[crypto.Address]uint64),
+	}
+}
+
+// balanceOf returns the staged balance of an Address, falling back to the LedgerState.
+func (s *ledgerStage) balanceOf(address crypto.Address) uint64 {
+	if balance, ok := s.balances[address]; ok {
+		return balance
+	}
+	if acc, ok := s.ls.accounts[address]; ok {
+		return acc.Balance
+	}
+	return 0
+}
+
+// nonceOf returns the staged nonce of an Address, falling back to the LedgerState.
+func (s *ledgerStage) nonceOf(address crypto.Address) uint64 {
+	if nonce, ok := s.nonces[address]; ok {
+		return nonce
+	}
+	if acc, ok := s.ls.accounts[address]; ok {
+		return acc.Nonce
+	}
+	return 0
+}
+
+// debit stages the removal of amount from the Address balance.
+// It returns false and stages nothing if the balance is not sufficient.
+func (s *ledgerStage) debit(address crypto.Address, amount uint64) bool {
+	balance := s.balanceOf(address)
+	if balance < amount {
+		return false
+	}
+
+	s.balances[address] = balance - amount
+	return true
+}
+
+// credit stages the addition of amount to the Address balance.
+func (s *ledgerStage) credit(address crypto.Address, amount uint64) error {
+	balance, err := addBalance(s.balanceOf(address), amount)
+	if err != nil {
+		return err
+	}
+
+	s.balances[address] = balance
+	return nil
+}
+
+// applyTx stages a Transaction: the sender Nonce is checked and incremented,
+// the sender is debited of the Value plus Fee and the recipient credited of the Value.
+func (s *ledgerStage) applyTx(tx *Transaction) error {
+	if tx == nil {
+		return BlockNilTransaction
+	}
+
+	from, err := tx.SignerAddress()
+	if err != nil {
+		return err
+	}
+
+	if tx.Nonce != s.nonceOf(from) {
+		return fmt.Errorf("Tx [%s] nonce %d, expected %d: %w", tx.Hash(TxHasher{}).String(), tx.Nonce, s.nonceOf(from), TxInvalidNonce)
+	}
+
+	cost, err := addBalance(tx.Value, tx.Fee)
+	if err != nil {
+		return err
+	}
+
+	if !s.debit(from, cost) {
+		return fmt.Errorf("Account %s does not have sufficient funds for Tx [%s] value plus fee.", from.String(), tx.Hash(TxHasher{}).String())
+	}
+	s.nonces[from] = tx.Nonce + 1
+
+	return s.credit(tx.To, tx.Value)
+}
+
+// commit writes the staged balances and nonces to the LedgerState,
+// creating the Accounts that do not exist yet.
+func (s *ledgerStage) commit() {
+	for address, balance := range s.balances {
+		s.accountOf(address).Balance = balance
+	}
+	for address, nonce := range s.nonces {
+		s.accountOf(address).Nonce = nonce
+	}
+}
+
+// accountOf returns the LedgerState Account of an Address, creating it if needed.
+func (s *ledgerStage) accountOf(address crypto.Address) *Account {
+	acc, ok := s.ls.accounts[address]
+	if !ok {
+		acc = &Account{Address: address}
+		s.ls.accounts[address] = acc
+	}
+	return acc
+}
+
+// addBalance returns the sum of two balances or BalanceOverflow if it overflows.
+func addBalance(a, b uint64) (uint64, error) {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return 0, BalanceOverflow
+	}
+
+	return sum, nil
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"math"
 	"testing"
 
 	"github.com/pacokleitz/ambula/crypto"
@@ -85,3 +86,314 @@ func TestLedgerValueWithoutFunds(t *testing.T) {
 	err = ledger.Transfer(fromAddress, toAddress, 1)
 	assert.NotNil(t, err)
 }
+
+func TestNewFundedLedger(t *testing.T) {
+	// Generate funded Accounts.
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 3, 100)
+	assert.Nil(t, err)
+	assert.Len(t, privKeys, 3)
+
+	// Check that every Account reports the allocated balance.
+	for _, privKey := range privKeys {
+		balance, err := ledger.GetBalance(privKey.PublicKey().Address())
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(100), balance)
+	}
+
+	// Create a LedgerState from explicit allocations.
+	allocations := map[crypto.Address]uint64{
+		privKeys[0].PublicKey().Address(): 1,
+		privKeys[1].PublicKey().Address(): 42,
+	}
+	ledger = NewFundedLedger(allocations)
+
+	// Check that the LedgerState reports the exact allocated balances.
+	for address, allocated := range allocations {
+		balance, err := ledger.GetBalance(address)
+		assert.Nil(t, err)
+		assert.Equal(t, allocated, balance)
+	}
+
+	// Check that unallocated Accounts do not exist.
+	_, err = ledger.GetAccount(privKeys[2].PublicKey().Address())
+	assert.NotNil(t, err)
+
+	// Check that the same seed derives the same keys and another seed different ones.
+	sameKeys, _, err := NewFundedLedgerWithKeys([]byte("ambula"), 3, 100)
+	assert.Nil(t, err)
+	otherKeys, _, err := NewFundedLedgerWithKeys([]byte("other"), 3, 100)
+	assert.Nil(t, err)
+	for i := range privKeys {
+		assert.Equal(t, privKeys[i].Bytes(), sameKeys[i].Bytes())
+		assert.NotEqual(t, privKeys[i].Bytes(), otherKeys[i].Bytes())
+	}
+}
+
+func TestLedgerApplyBlockFees(t *testing.T) {
+	// Fund two senders and generate the Block producer and receiver keys.
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 2, 100)
+	assert.Nil(t, err)
+	producerPrivKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+	toPrivKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+	to := toPrivKey.PublicKey().Address()
+
+	// Build a Block with multiple Transactions paying fees.
+	txx := []*Transaction{
+		NewTransaction(nil, to, 10, 2, 0, 0),
+		NewTransaction(nil, to, 5, 3, 1, 0),
+		NewTransaction(nil, to, 20, 1, 0, 0),
+	}
+	assert.Nil(t, txx[0].Sign(privKeys[0]))
+	assert.Nil(t, txx[1].Sign(privKeys[0]))
+	assert.Nil(t, txx[2].Sign(privKeys[1]))
+
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTxx(txx))
+	assert.Nil(t, b.Sign(producerPrivKey))
+
+	// Apply the Block with a block reward.
+	assert.Nil(t, ledger.ApplyBlock(b, 0, 50))
+
+	// Check that the senders paid value plus fee.
+	balance, err := ledger.GetBalance(privKeys[0].PublicKey().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(80), balance)
+	balance, err = ledger.GetBalance(privKeys[1].PublicKey().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(79), balance)
+
+	// Check that the receiver got the values only.
+	balance, err = ledger.GetBalance(to)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(35), balance)
+
+	// Check that the producer got the accumulated fees plus the reward.
+	balance, err = ledger.GetBalance(producerPrivKey.PublicKey().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(56), balance)
+}
+
+func TestLedgerApplyBlockEd25519(t *testing.T) {
+	// Fund an Ed25519 sender and generate an Ed25519 Block producer.
+	sender, err := crypto.GenerateEd25519PrivateKey()
+	assert.Nil(t, err)
+	producer, err := crypto.GenerateEd25519PrivateKey()
+	assert.Nil(t, err)
+	from := sender.PublicKey().Address()
+	ledger := NewFundedLedger(map[crypto.Address]uint64{from: 100})
+	toPrivKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+	to := toPrivKey.PublicKey().Address()
+
+	// Build and sign a Block with an Ed25519 Transaction.
+	tx := NewTransaction(nil, to, 10, 2, 0, 0)
+	assert.Nil(t, tx.Sign(sender))
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTx(tx))
+	assert.Nil(t, b.Sign(producer))
+
+	// Check that the Block is applied using the carried PublicKeys.
+	assert.Nil(t, ledger.ApplyBlock(b, 0, 50))
+	balance, err := ledger.GetBalance(from)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(88), balance)
+	balance, err = ledger.GetBalance(producer.PublicKey().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(52), balance)
+
+	// Check that the Block is rejected once a carried PublicKey is swapped.
+	otherSender, err := crypto.GenerateEd25519PrivateKey()
+	assert.Nil(t, err)
+	tx.SignerKey = otherSender.PublicKey()
+	assert.NotNil(t, b.VerifyData())
+}
+
+func TestLedgerApplyBlockFeeWithoutFunds(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 1, 100)
+	assert.Nil(t, err)
+	from := privKeys[0].PublicKey().Address()
+	toPrivKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	// Build a Block where the sender covers the first value but not the second value plus fee.
+	txx := []*Transaction{
+		NewTransaction(nil, toPrivKey.PublicKey().Address(), 50, 0, 0, 0),
+		NewTransaction(nil, toPrivKey.PublicKey().Address(), 50, 1, 1, 0),
+	}
+	assert.Nil(t, txx[0].Sign(privKeys[0]))
+	assert.Nil(t, txx[1].Sign(privKeys[0]))
+
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTxx(txx))
+	assert.Nil(t, b.Sign(privKeys[0]))
+
+	// Check that the Block is rejected and no balance changed.
+	assert.NotNil(t, ledger.ApplyBlock(b, 0, 0))
+	balance, err := ledger.GetBalance(from)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), balance)
+	_, err = ledger.GetAccount(toPrivKey.PublicKey().Address())
+	assert.NotNil(t, err)
+}
+
+func TestLedgerApplyBlockNonces(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 1, 100)
+	assert.Nil(t, err)
+	from := privKeys[0].PublicKey().Address()
+	toPrivKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+	to := toPrivKey.PublicKey().Address()
+
+	// Apply a Block with a Tx using the sender current nonce.
+	tx := NewTransaction(nil, to, 10, 0, 0, 0)
+	assert.Nil(t, tx.Sign(privKeys[0]))
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTx(tx))
+	assert.Nil(t, b.Sign(privKeys[0]))
+	assert.Nil(t, ledger.ApplyBlock(b, 0, 0))
+
+	// Check that the sender nonce was incremented.
+	nonce, err := ledger.GetNonce(from)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), nonce)
+
+	// Replay the applied Tx in another Block and check it is rejected.
+	replayBlock := randomBlockWithoutSignature(t, 2, crypto.Hash{})
+	assert.Nil(t, replayBlock.AddTx(tx))
+	assert.Nil(t, replayBlock.Sign(privKeys[0]))
+	assert.ErrorIs(t, ledger.ApplyBlock(replayBlock, 0, 0), TxInvalidNonce)
+
+	// Apply out of order nonces and check they are rejected.
+	txx := []*Transaction{
+		NewTransaction(nil, to, 10, 0, 2, 0),
+		NewTransaction(nil, to, 10, 0, 1, 0),
+	}
+	assert.Nil(t, txx[0].Sign(privKeys[0]))
+	assert.Nil(t, txx[1].Sign(privKeys[0]))
+	outOfOrderBlock := randomBlockWithoutSignature(t, 2, crypto.Hash{})
+	assert.Nil(t, outOfOrderBlock.AddTxx(txx))
+	assert.Nil(t, outOfOrderBlock.Sign(privKeys[0]))
+	assert.ErrorIs(t, ledger.ApplyBlock(outOfOrderBlock, 0, 0), TxInvalidNonce)
+
+	// Check that the rejected Blocks left the nonce and balance untouched.
+	nonce, err = ledger.GetNonce(from)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), nonce)
+	balance, err := ledger.GetBalance(from)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(90), balance)
+}
+
+func TestLedgerListAccounts(t *testing.T) {
+	// Create a LedgerState with several funded Accounts.
+	allocations := map[crypto.Address]uint64{
+		{0x03}: 3,
+		{0x01}: 1,
+		{0x02}: 2,
+	}
+	ledger := NewFundedLedger(allocations)
+	assert.Equal(t, 3, ledger.Len())
+
+	// Check that the Accounts are sorted by Address with correct balances.
+	accounts := ledger.Accounts()
+	assert.Len(t, accounts, 3)
+	for i, acc := range accounts {
+		assert.Equal(t, crypto.Address{byte(i + 1)}, acc.Address)
+		assert.Equal(t, uint64(i+1), acc.Balance)
+	}
+
+	// Check that the returned Accounts are copies.
+	accounts[0].Balance = 42
+	balance, err := ledger.GetBalance(crypto.Address{0x01})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), balance)
+}
+
+func TestLedgerTransferBatch(t *testing.T) {
+	a, b, c := crypto.Address{0x0a}, crypto.Address{0x0b}, crypto.Address{0x0c}
+	ledger := NewFundedLedger(map[crypto.Address]uint64{a: 100, b: 10})
+
+	// Check that a batch can spend funds received earlier in the batch.
+	err := ledger.TransferBatch([]TransferOp{
+		{From: a, To: b, Amount: 60},
+		{From: b, To: c, Amount: 70},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []*Account{
+		{Address: a, Balance: 40},
+		{Address: b, Balance: 0},
+		{Address: c, Balance: 70},
+	}, ledger.Accounts())
+
+	// Check that a failing transfer in the middle of the batch leaves no partial state.
+	before := ledger.Accounts()
+	failingBatches := [][]TransferOp{
+		{{From: a, To: b, Amount: 10}, {From: b, To: c, Amount: 11}, {From: c, To: a, Amount: 1}},
+		{{From: a, To: b, Amount: 10}, {From: crypto.Address{0xff}, To: c, Amount: 0}, {From: c, To: a, Amount: 1}},
+		{{From: a, To: b, Amount: 10}, {From: c, To: crypto.Address{0xfe}, Amount: 71}},
+	}
+	for _, ops := range failingBatches {
+		assert.NotNil(t, ledger.TransferBatch(ops))
+		assert.Equal(t, before, ledger.Accounts())
+	}
+
+	// Check that an overflowing transfer is rejected.
+	ledger = NewFundedLedger(map[crypto.Address]uint64{a: 1, b: math.MaxUint64})
+	err = ledger.TransferBatch([]TransferOp{{From: a, To: b, Amount: 1}})
+	assert.ErrorIs(t, err, BalanceOverflow)
+}
+
+func TestLedgerApplyBlockChainID(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 1, 100)
+	assert.Nil(t, err)
+	from := privKeys[0].PublicKey().Address()
+
+	// Build a Block with a Tx signed for chain 1.
+	tx := NewTransaction(nil, crypto.Address{0x42}, 10, 0, 0, 1)
+	assert.Nil(t, tx.Sign(privKeys[0]))
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTx(tx))
+	assert.Nil(t, b.Sign(privKeys[0]))
+
+	// Check that the Block is rejected on chain 2 and leaves the LedgerState untouched.
+	assert.ErrorIs(t, ledger.ApplyBlock(b, 2, 0), TxChainIDMismatch)
+	balance, err := ledger.GetBalance(from)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), balance)
+
+	// Check that the Block is applied on chain 1.
+	assert.Nil(t, ledger.ApplyBlock(b, 1, 0))
+	balance, err = ledger.GetBalance(from)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(90), balance)
+}
+
+func TestLedgerApplyBlockTamperedData(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 2, 100)
+	assert.Nil(t, err)
+	to := crypto.Address{0x42}
+
+	// Sign a Block holding a Tx from the first Account.
+	tx := NewTransaction(nil, to, 10, 0, 0, 0)
+	assert.Nil(t, tx.Sign(privKeys[0]))
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTx(tx))
+	assert.Nil(t, b.Sign(privKeys[0]))
+
+	// Swap the Block body for another validly signed Tx without updating the Header.
+	tamperedTx := NewTransaction(nil, to, 50, 0, 0, 0)
+	assert.Nil(t, tamperedTx.Sign(privKeys[1]))
+	b.Transactions = []*Transaction{tamperedTx}
+
+	// Check that the tampered Block is rejected and the LedgerState left untouched.
+	assert.NotNil(t, ledger.ApplyBlock(b, 0, 0))
+	for _, privKey := range privKeys {
+		balance, err := ledger.GetBalance(privKey.PublicKey().Address())
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(100), balance)
+	}
+	_, err = ledger.GetAccount(to)
+	assert.NotNil(t, err)
+}
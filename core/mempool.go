@@ -0,0 +1,181 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pacokleitz/ambula/crypto"
+)
+
+var (
+	MempoolFull      = errors.New("The mempool is full and the transaction fee is too low to evict another one.")
+	TxAlreadyPending = errors.New("The transaction is already pending in the mempool.")
+	TxNonceTaken     = errors.New("A transaction with the same sender and nonce and an higher or equal fee is already pending.")
+)
+
+// A pendingTx is a Transaction waiting in the Mempool.
+type pendingTx struct {
+	tx   *Transaction
+	from crypto.Address
+	seq  uint64 // Insertion sequence number
+}
+
+// The Mempool holds the pending Transactions waiting to be included in a Block.
+// Transactions are validated against the LedgerState on insertion.
+type Mempool struct {
+	lock    sync.RWMutex
+	ledger  *LedgerState
+	chainID uint32
+	maxSize int
+	seq     uint64
+	txx     map[crypto.Hash]*pendingTx
+}
+
+// NewMempool returns an empty Mempool validating Transactions against the
+// LedgerState and the chainID and holding at most maxSize Transactions.
+func NewMempool(ledger *LedgerState, chainID uint32, maxSize int) *Mempool {
+	return &Mempool{
+		ledger:  ledger,
+		chainID: chainID,
+		maxSize: maxSize,
+		txx:     make(map[crypto.Hash]*pendingTx),
+	}
+}
+
+// Add inserts a Transaction in the Mempool after checking its Signature, that it was
+// signed for the Mempool chainID and that its Nonce was not already used by the sender.
+// A pending Transaction with the same sender and Nonce is replaced if the new one pays
+// a higher Fee, otherwise the new one is rejected. When the Mempool is full the pending
+// Transaction with the lowest Fee is evicted if the new one pays more.
+func (mp *Mempool) Add(tx *Transaction) error {
+	from, err := tx.SignerAddress()
+	if err != nil {
+		return err
+	}
+
+	if tx.ChainID != mp.chainID {
+		return fmt.Errorf("Tx [%s] chain ID %d: %w", tx.Hash(TxHasher{}).String(), tx.ChainID, TxChainIDMismatch)
+	}
+
+	nonce, err := mp.ledger.GetNonce(from)
+	if err != nil {
+		return err
+	}
+
+	hash := tx.Hash(TxHasher{})
+	if tx.Nonce < nonce {
+		return fmt.Errorf("Tx [%s] nonce %d, expected at least %d: %w", hash.String(), tx.Nonce, nonce, TxInvalidNonce)
+	}
+
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	if _, ok := mp.txx[hash]; ok {
+		return TxAlreadyPending
+	}
+
+	for pendingHash, ptx := range mp.txx {
+		if ptx.from != from || ptx.tx.Nonce != tx.Nonce {
+			continue
+		}
+
+		if ptx.tx.Fee >= tx.Fee {
+			return fmt.Errorf("Tx [%s] nonce %d: %w", hash.String(), tx.Nonce, TxNonceTaken)
+		}
+
+		delete(mp.txx, pendingHash)
+		break
+	}
+
+	if len(mp.txx) >= mp.maxSize {
+		if err := mp.evictLowerFeeWithoutLock(tx.Fee); err != nil {
+			return err
+		}
+	}
+
+	mp.txx[hash] = &pendingTx{tx: tx, from: from, seq: mp.seq}
+	mp.seq++
+
+	return nil
+}
+
+// Pending returns the pending Transactions that can be applied in a Block, sorted by Nonce
+// across all senders and by insertion for the Transactions sharing a Nonce. For each sender
+// only the contiguous run of Nonces starting at its LedgerState Nonce is returned, the
+// Transactions after a Nonce gap are kept pending. The Transactions whose Nonce was
+// already used in the LedgerState are dropped from the Mempool.
+func (mp *Mempool) Pending() []*Transaction {
+	mp.lock.Lock()
+	nonces := make(map[crypto.Address]uint64)
+	pending := make([]*pendingTx, 0, len(mp.txx))
+	for hash, ptx := range mp.txx {
+		nonce, ok := nonces[ptx.from]
+		if !ok {
+			var err error
+			if nonce, err = mp.ledger.GetNonce(ptx.from); err != nil {
+				continue
+			}
+			nonces[ptx.from] = nonce
+		}
+
+		if ptx.tx.Nonce < nonce {
+			delete(mp.txx, hash)
+			continue
+		}
+		pending = append(pending, ptx)
+	}
+	mp.lock.Unlock()
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].seq < pending[j].seq })
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].tx.Nonce < pending[j].tx.Nonce })
+
+	txx := make([]*Transaction, 0, len(pending))
+	for _, ptx := range pending {
+		if ptx.tx.Nonce != nonces[ptx.from] {
+			continue
+		}
+		nonces[ptx.from]++
+		txx = append(txx, ptx.tx)
+	}
+
+	return txx
+}
+
+// Remove removes the Transactions matching the Hashes from the Mempool.
+func (mp *Mempool) Remove(hashes []crypto.Hash) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	for _, hash := range hashes {
+		delete(mp.txx, hash)
+	}
+}
+
+// Len returns the number of pending Transactions.
+func (mp *Mempool) Len() int {
+	mp.lock.RLock()
+	defer mp.lock.RUnlock()
+
+	return len(mp.txx)
+}
+
+// evictLowerFeeWithoutLock evicts the oldest pending Transaction with the lowest Fee
+// if this Fee is lower than fee. It returns MempoolFull if nothing was evicted.
+func (mp *Mempool) evictLowerFeeWithoutLock(fee uint64) error {
+	var lowest *pendingTx
+	var lowestHash crypto.Hash
+	for hash, ptx := range mp.txx {
+		if lowest == nil || ptx.tx.Fee < lowest.tx.Fee || (ptx.tx.Fee == lowest.tx.Fee && ptx.seq < lowest.seq) {
+			lowest, lowestHash = ptx, hash
+		}
+	}
+
+	if lowest == nil || lowest.tx.Fee >= fee {
+		return MempoolFull
+	}
+
+	delete(mp.txx, lowestHash)
+	return nil
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/pacokleitz/ambula/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMempoolAddDedup(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 1, 100)
+	assert.Nil(t, err)
+	mp := NewMempool(ledger, 0, 10)
+
+	// Add a signed Tx twice and check it is only pending once.
+	tx := NewTransaction(nil, crypto.Address{}, 1, 1, 0, 0)
+	assert.Nil(t, tx.Sign(privKeys[0]))
+	assert.Nil(t, mp.Add(tx))
+	assert.Equal(t, TxAlreadyPending, mp.Add(tx))
+	assert.Equal(t, []*Transaction{tx}, mp.Pending())
+
+	// Remove the Tx and check the Mempool is empty.
+	mp.Remove([]crypto.Hash{tx.Hash(TxHasher{})})
+	assert.Equal(t, 0, mp.Len())
+}
+
+func TestMempoolAddInvalid(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 1, 100)
+	assert.Nil(t, err)
+	from := privKeys[0].PublicKey().Address()
+	mp := NewMempool(ledger, 0, 10)
+
+	// Check that an unsigned Tx is rejected.
+	tx := NewTransaction(nil, crypto.Address{}, 1, 1, 0, 0)
+	assert.Equal(t, TxMissingSignature, mp.Add(tx))
+
+	// Check that a Tx tampered after signature is rejected (its signer is unknown).
+	assert.Nil(t, tx.Sign(privKeys[0]))
+	tx.Value = 99
+	tx.InvalidateHash()
+	assert.NotNil(t, mp.Add(tx))
+
+	// Check that a Tx reusing an already applied nonce is rejected.
+	ledger.accounts[from].Nonce = 1
+	staleTx := NewTransaction(nil, crypto.Address{}, 1, 1, 0, 0)
+	assert.Nil(t, staleTx.Sign(privKeys[0]))
+	assert.ErrorIs(t, mp.Add(staleTx), TxInvalidNonce)
+
+	assert.Equal(t, 0, mp.Len())
+}
+
+func TestMempoolFull(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 1, 100)
+	assert.Nil(t, err)
+	mp := NewMempool(ledger, 0, 2)
+
+	// Fill the Mempool.
+	txx := []*Transaction{
+		NewTransaction(nil, crypto.Address{}, 1, 2, 0, 0),
+		NewTransaction(nil, crypto.Address{}, 1, 1, 1, 0),
+		NewTransaction(nil, crypto.Address{}, 1, 1, 2, 0),
+		NewTransaction(nil, crypto.Address{}, 1, 3, 3, 0),
+	}
+	for _, tx := range txx {
+		assert.Nil(t, tx.Sign(privKeys[0]))
+	}
+	assert.Nil(t, mp.Add(txx[0]))
+	assert.Nil(t, mp.Add(txx[1]))
+
+	// Check that a Tx not paying more than the lowest fee is rejected.
+	assert.Equal(t, MempoolFull, mp.Add(txx[2]))
+
+	// Check that a Tx paying more evicts the lowest fee Tx.
+	assert.Nil(t, mp.Add(txx[3]))
+	assert.Equal(t, 2, mp.Len())
+	assert.Equal(t, TxAlreadyPending, mp.Add(txx[3]))
+
+	// Check that the Tx after the evicted nonce is kept pending but not returned.
+	assert.Equal(t, []*Transaction{txx[0]}, mp.Pending())
+}
+
+func TestMempoolPendingNonceOrder(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 1, 100)
+	assert.Nil(t, err)
+	mp := NewMempool(ledger, 0, 10)
+
+	// Add the sender Txs with decreasing nonces.
+	txx := []*Transaction{
+		NewTransaction(nil, crypto.Address{}, 1, 1, 1, 0),
+		NewTransaction(nil, crypto.Address{}, 1, 1, 0, 0),
+	}
+	for _, tx := range txx {
+		assert.Nil(t, tx.Sign(privKeys[0]))
+		assert.Nil(t, mp.Add(tx))
+	}
+
+	// Check that the pending Txs can be applied in order.
+	assert.Equal(t, []*Transaction{txx[1], txx[0]}, mp.Pending())
+}
+
+func TestMempoolAddSameNonce(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 1, 100)
+	assert.Nil(t, err)
+	mp := NewMempool(ledger, 0, 10)
+
+	tx := NewTransaction([]byte("a"), crypto.Address{}, 1, 2, 0, 0)
+	assert.Nil(t, tx.Sign(privKeys[0]))
+	assert.Nil(t, mp.Add(tx))
+
+	// Check that another Tx with the same sender and nonce but no higher fee is rejected.
+	sameFee := NewTransaction([]byte("b"), crypto.Address{}, 1, 2, 0, 0)
+	assert.Nil(t, sameFee.Sign(privKeys[0]))
+	assert.ErrorIs(t, mp.Add(sameFee), TxNonceTaken)
+	assert.Equal(t, []*Transaction{tx}, mp.Pending())
+
+	// Check that a higher fee replaces the pending Tx.
+	higherFee := NewTransaction([]byte("c"), crypto.Address{}, 1, 3, 0, 0)
+	assert.Nil(t, higherFee.Sign(privKeys[0]))
+	assert.Nil(t, mp.Add(higherFee))
+	assert.Equal(t, []*Transaction{higherFee}, mp.Pending())
+}
+
+func TestMempoolAddChainID(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 1, 100)
+	assert.Nil(t, err)
+	mp := NewMempool(ledger, 1, 10)
+
+	// Check that a Tx signed for another chain is rejected.
+	tx := NewTransaction(nil, crypto.Address{}, 1, 1, 0, 2)
+	assert.Nil(t, tx.Sign(privKeys[0]))
+	assert.ErrorIs(t, mp.Add(tx), TxChainIDMismatch)
+	assert.Equal(t, 0, mp.Len())
+
+	// Check that a Tx signed for the Mempool chain is accepted.
+	tx = NewTransaction(nil, crypto.Address{}, 1, 1, 0, 1)
+	assert.Nil(t, tx.Sign(privKeys[0]))
+	assert.Nil(t, mp.Add(tx))
+}
+
+func TestMempoolPendingNonceGap(t *testing.T) {
+	privKeys, ledger, err := NewFundedLedgerWithKeys([]byte("ambula"), 2, 100)
+	assert.Nil(t, err)
+	mp := NewMempool(ledger, 0, 10)
+
+	// Add Txs with nonces 0 and 2 for the first sender and 0 for the second one.
+	txx := []*Transaction{
+		NewTransaction(nil, crypto.Address{}, 1, 1, 0, 0),
+		NewTransaction(nil, crypto.Address{}, 1, 1, 2, 0),
+		NewTransaction(nil, crypto.Address{0x01}, 1, 1, 0, 0),
+	}
+	assert.Nil(t, txx[0].Sign(privKeys[0]))
+	assert.Nil(t, txx[1].Sign(privKeys[0]))
+	assert.Nil(t, txx[2].Sign(privKeys[1]))
+	for _, tx := range txx {
+		assert.Nil(t, mp.Add(tx))
+	}
+
+	// Check that the Tx after the gap is kept pending but not returned.
+	pending := mp.Pending()
+	assert.Equal(t, []*Transaction{txx[0], txx[2]}, pending)
+	assert.Equal(t, 3, mp.Len())
+
+	// Check that a Block built from the pending Txs can be applied.
+	b := randomBlockWithoutSignature(t, 1, crypto.Hash{})
+	assert.Nil(t, b.AddTxx(pending))
+	assert.Nil(t, b.Sign(privKeys[0]))
+	assert.Nil(t, ledger.ApplyBlock(b, 0, 0))
+
+	// Check that the applied Txs are dropped and the gap is still not filled.
+	assert.Empty(t, mp.Pending())
+	assert.Equal(t, 1, mp.Len())
+
+	// Fill the gap and check that the run is returned.
+	gapTx := NewTransaction(nil, crypto.Address{}, 1, 1, 1, 0)
+	assert.Nil(t, gapTx.Sign(privKeys[0]))
+	assert.Nil(t, mp.Add(gapTx))
+	assert.Equal(t, []*Transaction{gapTx, txx[1]}, mp.Pending())
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/pacokleitz/ambula/crypto"
+)
+
+// Domain separation prefixes preventing a Merkle tree inner node
+// from being passed off as a leaf (second preimage attack).
+const (
+	MERKLE_LEAF_PREFIX byte = 0x00 // Prefix of the hashed Merkle tree leaves
+	MERKLE_NODE_PREFIX byte = 0x01 // Prefix of the hashed Merkle tree inner nodes
+)
+
+var (
+	MerkleIndexOutOfRange = errors.New("The Merkle leaf index is out of the leaves range.")
+	MerkleProofMismatch   = errors.New("The Merkle proof does not lead to the expected root.")
+)
+
+// MerkleLeafHash returns the Merkle tree leaf Hash of a Transaction computed
+// over its byte representation (including its Signature).
+func MerkleLeafHash(tx *Transaction) crypto.Hash {
+	return newLeafHasher().Hash(tx)
+}
+
+// A leafHasher computes Merkle tree leaf Hashes by streaming the Transactions
+// byte representation into a reusable blake2b hasher, so no buffer holding a
+// whole Transaction is allocated. A leafHasher is not safe for concurrent use.
+type leafHasher struct {
+	hasher  hash.Hash
+	scratch []byte
+	sum     []byte
+}
+
+// newLeafHasher returns a pointer to a leafHasher.
+func newLeafHasher() *leafHasher {
+	hasher, err := blake2b.New256(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return &leafHasher{
+		hasher:  hasher,
+		scratch: make([]byte, 0, crypto.ADDR_BYTE_SIZE+TX_FIXED_BYTE_SIZE),
+		sum:     make([]byte, 0, crypto.HASH_BYTE_SIZE),
+	}
+}
+
+// Hash returns the Merkle tree leaf Hash of a Transaction.
+func (lh *leafHasher) Hash(tx *Transaction) crypto.Hash {
+	lh.hasher.Reset()
+	lh.scratch = append(lh.scratch[:0], MERKLE_LEAF_PREFIX)
+	if _, err := lh.hasher.Write(lh.scratch); err != nil {
+		panic(err)
+	}
+
+	scratch, err := tx.writeBytes(lh.hasher, lh.scratch)
+	if err != nil {
+		panic(err)
+	}
+	lh.scratch = scratch
+
+	lh.sum = lh.hasher.Sum(lh.sum[:0])
+
+	var h crypto.Hash
+	copy(h[:], lh.sum)
+	return h
+}
+
+// merkleNodeHash returns the Hash of a Merkle tree inner node given its children.
+func merkleNodeHash(left, right crypto.Hash) crypto.Hash {
+	var buf [1 + 2*crypto.HASH_BYTE_SIZE]byte
+	buf[0] = MERKLE_NODE_PREFIX
+	copy(buf[1:], left[:])
+	copy(buf[1+crypto.HASH_BYTE_SIZE:], right[:])
+	return crypto.Hash(blake2b.Sum256(buf[:]))
+}
+
+// ComputeMerkleRoot returns the root Hash of the Merkle tree built over the leaves.
+// A node without sibling is promoted unchanged to the next level instead of being
+// duplicated, so two different lists of leaves can not share the same root.
+// The root of an empty list of leaves is the Hash of an empty input.
+func ComputeMerkleRoot(leaves []crypto.Hash) crypto.Hash {
+	if len(leaves) == 0 {
+		return crypto.Hash(blake2b.Sum256(nil))
+	}
+
+	level := make([]crypto.Hash, len(leaves))
+	copy(level, leaves)
+
+	for len(level) > 1 {
+		next := level[:0]
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, merkleNodeHash(level[i], level[i+1]))
+		}
+		level = next
+	}
+
+	return level[0]
+}
+
+// A MerkleStep is the sibling Hash met on the path from a leaf to the Merkle root.
+type MerkleStep struct {
+	Hash crypto.Hash // Hash of the sibling node
+	Left bool        // Whether the sibling node is on the left of the path node
+}
+
+// A MerkleProof is the Merkle branch proving that a leaf is part of a Merkle tree.
+// Levels where the path node has no sibling (and is promoted unchanged) have no MerkleStep.
+type MerkleProof struct {
+	Steps []MerkleStep
+}
+
+// ComputeMerkleProof returns the MerkleProof of the leaf at index in the Merkle tree
+// built over the leaves by ComputeMerkleRoot.
+func ComputeMerkleProof(leaves []crypto.Hash, index int) (*MerkleProof, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("Index %d of %d leaves: %w", index, len(leaves), MerkleIndexOutOfRange)
+	}
+
+	level := make([]crypto.Hash, len(leaves))
+	copy(level, leaves)
+
+	proof := &MerkleProof{}
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof.Steps = append(proof.Steps, MerkleStep{
+				Hash: level[sibling],
+				Left: sibling < index,
+			})
+		}
+
+		next := level[:0]
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, merkleNodeHash(level[i], level[i+1]))
+		}
+		level = next
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// Root returns the Merkle root obtained by hashing the leaf along the MerkleProof path.
+func (p *MerkleProof) Root(leaf crypto.Hash) crypto.Hash {
+	node := leaf
+	for _, step := range p.Steps {
+		if step.Left {
+			node = merkleNodeHash(step.Hash, node)
+		} else {
+			node = merkleNodeHash(node, step.Hash)
+		}
+	}
+
+	return node
+}
+
+// VerifyInclusion checks that the MerkleProof proves the Transaction is part of the
+// Block described by the Header, without needing the other Block Transactions.
+func VerifyInclusion(tx *Transaction, proof *MerkleProof, header *Header) error {
+	if header == nil {
+		return BlockMissingHeader
+	}
+
+	if proof.Root(MerkleLeafHash(tx)) != header.DataHash {
+		return fmt.Errorf("Tx [%s] data hash [%s]: %w", tx.Hash(TxHasher{}).String(), header.DataHash.String(), MerkleProofMismatch)
+	}
+
+	return nil
+}
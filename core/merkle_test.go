@@ -0,0 +1,135 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/pacokleitz/ambula/crypto"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	a := crypto.Hash{0x0a}
+	b := crypto.Hash{0x0b}
+	c := crypto.Hash{0x0c}
+
+	// Check that a single leaf is its own root.
+	assert.Equal(t, a, ComputeMerkleRoot([]crypto.Hash{a}))
+
+	// Check that an odd leaf is promoted instead of duplicated.
+	expected := merkleNodeHash(merkleNodeHash(a, b), c)
+	assert.Equal(t, expected, ComputeMerkleRoot([]crypto.Hash{a, b, c}))
+	assert.NotEqual(t, expected, ComputeMerkleRoot([]crypto.Hash{a, b, c, c}))
+
+	// Check that the root depends on the leaves order.
+	assert.NotEqual(t, expected, ComputeMerkleRoot([]crypto.Hash{b, a, c}))
+}
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 7; n++ {
+		leaves := make([]crypto.Hash, n)
+		for i := range leaves {
+			leaves[i] = crypto.Hash{byte(i + 1)}
+		}
+		root := ComputeMerkleRoot(leaves)
+
+		// Check that the proof of every leaf leads to the root.
+		for i := range leaves {
+			proof, err := ComputeMerkleProof(leaves, i)
+			assert.Nil(t, err)
+			assert.Equal(t, root, proof.Root(leaves[i]))
+			assert.NotEqual(t, root, proof.Root(crypto.Hash{0xff}))
+		}
+
+		// Check that an out of range index is rejected.
+		_, err := ComputeMerkleProof(leaves, n)
+		assert.ErrorIs(t, err, MerkleIndexOutOfRange)
+	}
+}
+
+func TestBlockInclusionProof(t *testing.T) {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	b := randomBlockWithoutSignature(t, 0, crypto.Hash{})
+	for i := 0; i < 5; i++ {
+		tx := genTxWithoutSignature(t)
+		tx.Nonce = uint64(i)
+		assert.Nil(t, tx.Sign(privKey))
+		assert.Nil(t, b.AddTx(tx))
+	}
+
+	// Check that a Transaction inclusion is verified against the Header only.
+	txHash := b.Transactions[3].Hash(TxHasher{})
+	tx, proof, err := b.InclusionProof(txHash)
+	assert.Nil(t, err)
+	assert.Equal(t, b.Transactions[3], tx)
+	assert.Nil(t, VerifyInclusion(tx, proof, b.Header))
+
+	// Check that a forged Transaction or proof is rejected.
+	forged := *tx
+	forged.Value++
+	forged.InvalidateHash()
+	assert.ErrorIs(t, VerifyInclusion(&forged, proof, b.Header), MerkleProofMismatch)
+	proof.Steps[0].Left = !proof.Steps[0].Left
+	assert.ErrorIs(t, VerifyInclusion(tx, proof, b.Header), MerkleProofMismatch)
+
+	// Check that a Transaction missing from the Block has no proof.
+	_, _, err = b.InclusionProof(crypto.Hash{0x42})
+	assert.ErrorIs(t, err, TxNotInBlock)
+}
+
+func TestComputeDataHashStreaming(t *testing.T) {
+	txx := genLargeTxx(t, 16, 1<<16)
+
+	// Check that the streaming and buffered paths produce the same Hash.
+	// Their memory usage is compared by the benchmarks below.
+	dataHash, err := ComputeDataHash(txx)
+	assert.Nil(t, err)
+	assert.Equal(t, computeDataHashBuffered(txx), dataHash)
+}
+
+func BenchmarkComputeDataHashLargeStreaming(b *testing.B) {
+	txx := genLargeTxx(b, 16, 1<<16)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = ComputeDataHash(txx)
+	}
+}
+
+func BenchmarkComputeDataHashLargeBuffered(b *testing.B) {
+	txx := genLargeTxx(b, 16, 1<<16)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = computeDataHashBuffered(txx)
+	}
+}
+
+// computeDataHashBuffered is the reference DataHash computation buffering
+// every Transaction byte representation before hashing it.
+func computeDataHashBuffered(txx []*Transaction) crypto.Hash {
+	leaves := make([]crypto.Hash, len(txx))
+	for i, tx := range txx {
+		leaves[i] = crypto.Hash(blake2b.Sum256(append([]byte{MERKLE_LEAF_PREFIX}, tx.Bytes()...)))
+	}
+
+	return ComputeMerkleRoot(leaves)
+}
+
+// genLargeTxx returns n signed Transactions carrying dataSize bytes of Data each.
+func genLargeTxx(t assert.TestingT, n int, dataSize int) []*Transaction {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	txx := make([]*Transaction, n)
+	for i := range txx {
+		txx[i] = NewTransaction(make([]byte, dataSize), privKey.PublicKey().Address(), 1, 1, uint64(i), 0)
+		assert.Nil(t, txx[i].Sign(privKey))
+	}
+
+	return txx
+}
@@ -0,0 +1,304 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pacokleitz/ambula/crypto"
+)
+
+// BLOCK_FILE_EXT is the extension of the files storing a Block in a FileStorage.
+const BLOCK_FILE_EXT = ".block"
+
+var (
+	BlockNotFound    = errors.New("The block can not be found in the storage.")
+	NoCommonAncestor = errors.New("The chains do not share a common ancestor block.")
+)
+
+// The Storage interface exposes the methods needed to persist Blocks.
+type Storage interface {
+	PutBlock(*Block) error
+	GetBlock(crypto.Hash) (*Block, error)
+	Has(crypto.Hash) bool
+	IterateFromHeight(uint32, func(*Block) error) error
+}
+
+// MemoryStorage is an in-memory only Storage implementation.
+type MemoryStorage struct {
+	lock   sync.RWMutex
+	blocks map[crypto.Hash]*Block
+}
+
+// NewMemoryStorage returns an empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		blocks: make(map[crypto.Hash]*Block),
+	}
+}
+
+// PutBlock stores the Block indexed by its HeaderHash.
+func (s *MemoryStorage) PutBlock(b *Block) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.blocks[b.HeaderHash(BlockHasher{})] = b
+	return nil
+}
+
+// GetBlock returns the Block matching the HeaderHash.
+func (s *MemoryStorage) GetBlock(hash crypto.Hash) (*Block, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	b, ok := s.blocks[hash]
+	if !ok {
+		return nil, fmt.Errorf("Block [%s]: %w", hash.String(), BlockNotFound)
+	}
+
+	return b, nil
+}
+
+// Has checks that a Block matching the HeaderHash is stored.
+func (s *MemoryStorage) Has(hash crypto.Hash) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	_, ok := s.blocks[hash]
+	return ok
+}
+
+// IterateFromHeight calls fn on every stored Block whose Height is at least height,
+// by increasing Height. The iteration stops on the first error returned by fn.
+func (s *MemoryStorage) IterateFromHeight(height uint32, fn func(*Block) error) error {
+	s.lock.RLock()
+	blocks := make([]*Block, 0, len(s.blocks))
+	for _, b := range s.blocks {
+		if b.Height >= height {
+			blocks = append(blocks, b)
+		}
+	}
+	s.lock.RUnlock()
+
+	sortBlocks(blocks)
+	for _, b := range blocks {
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindCommonAncestor walks back from the foreign otherTip, fetching the parent Blocks
+// using fetch, until it reaches a Block held by the Storage which is returned as the fork point.
+// Every fetched parent must match the PrevBlockHash and the Height of its child so a
+// misbehaving fetch can not make the walk loop or jump to an unrelated chain.
+func FindCommonAncestor(storage Storage, otherTip *Block, fetch func(crypto.Hash) (*Block, error)) (*Block, error) {
+	b := otherTip
+	for {
+		if b == nil || b.Header == nil {
+			return nil, BlockMissingHeader
+		}
+
+		hash := b.HeaderHash(BlockHasher{})
+		if storage.Has(hash) {
+			return storage.GetBlock(hash)
+		}
+
+		if b.Height == 0 {
+			return nil, fmt.Errorf("Genesis block [%s]: %w", hash.String(), NoCommonAncestor)
+		}
+
+		parent, err := fetch(b.PrevBlockHash)
+		if err != nil {
+			return nil, fmt.Errorf("Fetching block [%s] parent failed: %w", hash.String(), err)
+		}
+
+		if parent == nil || parent.Header == nil {
+			return nil, BlockMissingHeader
+		}
+
+		parentHash := parent.HeaderHash(BlockHasher{})
+		if parentHash != b.PrevBlockHash || parent.Height+1 != b.Height {
+			return nil, fmt.Errorf("Fetched block [%s] is not the parent of block [%s].", parentHash.String(), hash.String())
+		}
+
+		b = parent
+	}
+}
+
+// FileStorage is a Storage implementation writing each Block gob encoded
+// in its own file of a directory. The Blocks Heights are indexed in memory
+// when the FileStorage is opened so the iteration does not decode every file.
+type FileStorage struct {
+	lock    sync.RWMutex
+	dir     string
+	heights map[crypto.Hash]uint32
+}
+
+// NewFileStorage opens the FileStorage in the directory dir, creating it if needed,
+// and indexes the Blocks already stored in it.
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := &FileStorage{
+		dir:     dir,
+		heights: make(map[crypto.Hash]uint32),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, BLOCK_FILE_EXT) {
+			continue
+		}
+
+		hash, err := crypto.HashFromString(strings.TrimSuffix(name, BLOCK_FILE_EXT))
+		if err != nil {
+			return nil, fmt.Errorf("Block file %s has an invalid name: %w", name, err)
+		}
+
+		b, err := s.readBlock(hash)
+		if err != nil {
+			return nil, err
+		}
+		s.heights[hash] = b.Height
+	}
+
+	return s, nil
+}
+
+// PutBlock writes the Block in a file named after its HeaderHash.
+// The file is written next to its destination then renamed so a crash
+// can not leave a partially written Block.
+func (s *FileStorage) PutBlock(b *Block) error {
+	hash := b.HeaderHash(BlockHasher{})
+
+	tmp, err := os.CreateTemp(s.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := b.Encode(NewGobBlockEncoder(tmp)); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := os.Rename(tmp.Name(), s.blockPath(hash)); err != nil {
+		return err
+	}
+	s.heights[hash] = b.Height
+
+	return nil
+}
+
+// GetBlock reads the Block matching the HeaderHash from its file.
+func (s *FileStorage) GetBlock(hash crypto.Hash) (*Block, error) {
+	if !s.Has(hash) {
+		return nil, fmt.Errorf("Block [%s]: %w", hash.String(), BlockNotFound)
+	}
+
+	return s.readBlock(hash)
+}
+
+// Has checks that a Block matching the HeaderHash is stored.
+func (s *FileStorage) Has(hash crypto.Hash) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	_, ok := s.heights[hash]
+	return ok
+}
+
+// IterateFromHeight calls fn on every stored Block whose Height is at least height,
+// by increasing Height. The iteration stops on the first error returned by fn.
+func (s *FileStorage) IterateFromHeight(height uint32, fn func(*Block) error) error {
+	s.lock.RLock()
+	hashes := make([]crypto.Hash, 0, len(s.heights))
+	for hash, h := range s.heights {
+		if h >= height {
+			hashes = append(hashes, hash)
+		}
+	}
+	s.lock.RUnlock()
+
+	blocks := make([]*Block, 0, len(hashes))
+	for _, hash := range hashes {
+		b, err := s.readBlock(hash)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, b)
+	}
+
+	sortBlocks(blocks)
+	for _, b := range blocks {
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readBlock decodes the Block stored in the file matching the HeaderHash
+// and checks that the decoded Block HeaderHash matches the file name.
+func (s *FileStorage) readBlock(hash crypto.Hash) (*Block, error) {
+	f, err := os.Open(s.blockPath(hash))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	b := new(Block)
+	if err := b.Decode(NewGobBlockDecoder(f)); err != nil {
+		return nil, fmt.Errorf("Block [%s] decoding failed: %w", hash.String(), err)
+	}
+
+	if b.Header == nil {
+		return nil, fmt.Errorf("Block [%s]: %w", hash.String(), BlockMissingHeader)
+	}
+
+	if headerHash := b.HeaderHash(BlockHasher{}); headerHash != hash {
+		return nil, fmt.Errorf("Block file [%s] holds block [%s].", hash.String(), headerHash.String())
+	}
+
+	return b, nil
+}
+
+// blockPath returns the path of the file storing the Block matching the HeaderHash.
+func (s *FileStorage) blockPath(hash crypto.Hash) string {
+	return filepath.Join(s.dir, hash.String()+BLOCK_FILE_EXT)
+}
+
+// sortBlocks sorts Blocks by increasing Height then HeaderHash so that
+// Blocks sharing a Height are always iterated in the same order.
+func sortBlocks(blocks []*Block) {
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Height != blocks[j].Height {
+			return blocks[i].Height < blocks[j].Height
+		}
+		hi, hj := blocks[i].HeaderHash(BlockHasher{}), blocks[j].HeaderHash(BlockHasher{})
+		return bytes.Compare(hi[:], hj[:]) < 0
+	})
+}
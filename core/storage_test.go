@@ -0,0 +1,173 @@
+package core
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pacokleitz/ambula/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	s := NewMemoryStorage()
+	blocks := putChain(t, s, 3)
+
+	// Check that the stored Blocks can be fetched and iterated.
+	assertStoredChain(t, s, blocks)
+}
+
+func TestFileStorageReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := NewFileStorage(dir)
+	assert.Nil(t, err)
+	blocks := putChain(t, s, 3)
+	assertStoredChain(t, s, blocks)
+
+	// Reopen the FileStorage from the same directory.
+	reopened, err := NewFileStorage(dir)
+	assert.Nil(t, err)
+
+	// Check that the reopened FileStorage recovers the same Blocks.
+	assertStoredChain(t, reopened, blocks)
+
+	// Rebuild the chain height and last Block Hash from the persisted Blocks.
+	var last *Block
+	assert.Nil(t, reopened.IterateFromHeight(0, func(b *Block) error {
+		last = b
+		return nil
+	}))
+	assert.Equal(t, blocks[len(blocks)-1].Height, last.Height)
+	assert.Equal(t, blocks[len(blocks)-1].HeaderHash(BlockHasher{}), last.HeaderHash(BlockHasher{}))
+}
+
+func TestFileStorageCorruptedFile(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStorage(dir)
+	assert.Nil(t, err)
+	blocks := putChain(t, s, 2)
+	hash := blocks[1].HeaderHash(BlockHasher{})
+
+	// Overwrite the second Block file with the first Block.
+	f, err := os.Create(s.blockPath(hash))
+	assert.Nil(t, err)
+	assert.Nil(t, blocks[0].Encode(NewGobBlockEncoder(f)))
+	assert.Nil(t, f.Close())
+
+	// Check that the Block file not matching its name is rejected.
+	_, err = s.GetBlock(hash)
+	assert.NotNil(t, err)
+	_, err = NewFileStorage(dir)
+	assert.NotNil(t, err)
+
+	// Overwrite the second Block file with a Block without Header.
+	f, err = os.Create(s.blockPath(hash))
+	assert.Nil(t, err)
+	assert.Nil(t, (&Block{Signature: blocks[1].Signature}).Encode(NewGobBlockEncoder(f)))
+	assert.Nil(t, f.Close())
+
+	// Check that the Block without Header is rejected instead of panicking.
+	_, err = NewFileStorage(dir)
+	assert.ErrorIs(t, err, BlockMissingHeader)
+}
+
+func TestFindCommonAncestor(t *testing.T) {
+	storage := NewMemoryStorage()
+	local := putChain(t, storage, 5)
+
+	// Build a foreign chain forking after the third local Block.
+	foreign := make(map[crypto.Hash]*Block)
+	for _, b := range local[:3] {
+		foreign[b.HeaderHash(BlockHasher{})] = b
+	}
+	tip := local[2]
+	for i := 0; i < 4; i++ {
+		b, err := NewBlockFromPrevHeader(tip.Header, []*Transaction{})
+		assert.Nil(t, err)
+		b.Timestamp = tip.Timestamp - 1
+		foreign[b.HeaderHash(BlockHasher{})] = b
+		tip = b
+	}
+	fetch := func(hash crypto.Hash) (*Block, error) {
+		b, ok := foreign[hash]
+		if !ok {
+			return nil, BlockNotFound
+		}
+		return b, nil
+	}
+
+	// Check that the fork point is found.
+	ancestor, err := FindCommonAncestor(storage, tip, fetch)
+	assert.Nil(t, err)
+	assert.Equal(t, local[2], ancestor)
+
+	// Check that a local tip is its own common ancestor.
+	ancestor, err = FindCommonAncestor(storage, local[4], fetch)
+	assert.Nil(t, err)
+	assert.Equal(t, local[4], ancestor)
+
+	// Check that a chain with another genesis Block has no common ancestor.
+	otherStorage := NewMemoryStorage()
+	other := putChain(t, otherStorage, 3)
+	fetchOther := func(hash crypto.Hash) (*Block, error) {
+		return otherStorage.GetBlock(hash)
+	}
+	_, err = FindCommonAncestor(storage, other[2], fetchOther)
+	assert.ErrorIs(t, err, NoCommonAncestor)
+
+	// Check that a fetch returning a Block which is not the parent is rejected.
+	_, err = FindCommonAncestor(storage, other[2], func(crypto.Hash) (*Block, error) {
+		return other[2], nil
+	})
+	assert.NotNil(t, err)
+}
+
+// putChain stores a chain of n signed Blocks and returns them by increasing Height.
+func putChain(t *testing.T, s Storage, n int) []*Block {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	blocks := make([]*Block, n)
+	blocks[0] = randomBlockWithoutSignature(t, 0, crypto.Hash{})
+	for i := 1; i < n; i++ {
+		blocks[i], err = NewBlockFromPrevHeader(blocks[i-1].Header, []*Transaction{})
+		assert.Nil(t, err)
+	}
+
+	for _, b := range blocks {
+		assert.Nil(t, b.Sign(privKey))
+		assert.Nil(t, s.PutBlock(b))
+	}
+
+	return blocks
+}
+
+// assertStoredChain checks that the Storage holds exactly the chain of blocks.
+func assertStoredChain(t *testing.T, s Storage, blocks []*Block) {
+	for _, b := range blocks {
+		hash := b.HeaderHash(BlockHasher{})
+		assert.True(t, s.Has(hash))
+
+		fetched, err := s.GetBlock(hash)
+		assert.Nil(t, err)
+		assert.Equal(t, b.Header, fetched.Header)
+		assert.Equal(t, b.Signature, fetched.Signature)
+	}
+
+	// Check that missing Blocks are reported as not found.
+	assert.False(t, s.Has(crypto.Hash{}))
+	_, err := s.GetBlock(crypto.Hash{})
+	assert.ErrorIs(t, err, BlockNotFound)
+
+	// Check that the iteration starts at the requested Height and follows the chain.
+	var iterated []crypto.Hash
+	assert.Nil(t, s.IterateFromHeight(1, func(b *Block) error {
+		iterated = append(iterated, b.HeaderHash(BlockHasher{}))
+		return nil
+	}))
+	expected := make([]crypto.Hash, 0, len(blocks)-1)
+	for _, b := range blocks[1:] {
+		expected = append(expected, b.HeaderHash(BlockHasher{}))
+	}
+	assert.Equal(t, expected, iterated)
+}
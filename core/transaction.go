@@ -1,44 +1,59 @@
 package core
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
-	"math"
+	"io"
 
 	"github.com/pacokleitz/ambula/crypto"
-	"github.com/pacokleitz/ambula/random"
 )
 
+// TX_FIXED_BYTE_SIZE is the length in bytes of the fixed size fields
+// (ChainID, Value, Fee, Nonce and the two length prefixes) in the Transaction byte representation.
+const TX_FIXED_BYTE_SIZE = 4 + 8 + 8 + 8 + 4 + 4
+
+// TX_SIGNER_KEY_FIXED_BYTE_SIZE is the length in bytes of the Scheme and the SignerKey
+// length prefix in the byte representation of a Transaction carrying its SignerKey.
+const TX_SIGNER_KEY_FIXED_BYTE_SIZE = 1 + 4
+
 var (
 	TxMissingSignature = errors.New("The verified transaction has no signature.")
+	TxChainIDMismatch  = errors.New("The transaction chain ID does not match the expected chain ID.")
+	TxInvalidNonce     = errors.New("The transaction nonce does not match the sender account nonce.")
 )
 
 // A Transaction is the object consumed for every data or value
 // modification in the Blockchain. A Transaction should be signed
 // by the From sender and have the To receiver PublicKey.
+// The ChainID is part of the signed content so a Transaction signed
+// for a chain can not be replayed on another one.
+// The SignerKey is only carried for a Scheme without PublicKey recovery.
 type Transaction struct {
+	ChainID   uint32
 	Data      []byte
 	To        crypto.Address
 	Value     uint64
+	Fee       uint64
 	Signature crypto.Signature
-	Nonce     int64
+	Nonce     uint64
+	Scheme    crypto.SignatureScheme
+	SignerKey crypto.PublicKey
 
 	hash crypto.Hash
 }
 
-// NewTransaction returns a Transaction with a random Nonce.
-func NewTransaction(data []byte, to crypto.Address, value uint64) *Transaction {
-	// Temporary until possible to query existing nonces
-	nonce, err := random.RandomInt(math.MaxInt64)
-	if err != nil {
-		panic(err)
-	}
-
+// NewTransaction returns a Transaction for the chainID.
+// The fee is paid by the sender to the Block producer on top of the value and
+// the nonce must match the sender Account nonce when the Transaction is applied.
+func NewTransaction(data []byte, to crypto.Address, value, fee, nonce uint64, chainID uint32) *Transaction {
 	return &Transaction{
-		To:    to,
-		Value: value,
-		Data:  data,
-		Nonce: nonce,
+		ChainID: chainID,
+		To:      to,
+		Value:   value,
+		Fee:     fee,
+		Data:    data,
+		Nonce:   nonce,
 	}
 }
 
@@ -57,27 +72,33 @@ func (tx *Transaction) InvalidateHash() {
 	tx.hash = crypto.Hash{}
 }
 
-// Sign a Transaction by signing the Transaction Hash and set the From field.
-func (tx *Transaction) Sign(privKey crypto.PrivateKey) error {
+// Sign a Transaction by signing the Transaction Hash with the Signer.
+// The Signer PublicKey is carried as the SignerKey if its scheme has no PublicKey recovery.
+func (tx *Transaction) Sign(signer crypto.Signer) error {
 	hash := tx.Hash(TxHasher{})
-	sig, err := privKey.Sign(hash)
+	sig, err := signer.Sign(hash)
 	if err != nil {
 		return err
 	}
 
 	tx.Signature = sig
+	tx.Scheme = signer.Scheme()
+	tx.SignerKey = nil
+	if !tx.Scheme.Recoverable() {
+		tx.SignerKey = signer.PublicKey()
+	}
 
 	return nil
 }
 
 // Signer returns the PublicKey of the Transaction signer.
 func (tx *Transaction) Signer() (crypto.PublicKey, error) {
-	if tx.Signature == nil {
+	if len(tx.Signature) == 0 {
 		return nil, TxMissingSignature
 	}
 
 	hash := tx.Hash(TxHasher{})
-	sigPubKey, err := tx.Signature.PublicKey(hash)
+	sigPubKey, err := crypto.SignerPublicKey(tx.Scheme, tx.SignerKey, hash, tx.Signature)
 	if err != nil {
 		return nil, fmt.Errorf("Tx [%s] signature verification failed.", hash.String())
 	}
@@ -85,6 +106,100 @@ func (tx *Transaction) Signer() (crypto.PublicKey, error) {
 	return sigPubKey, nil
 }
 
+// SignerAddress returns the Address of the Transaction signer.
+func (tx *Transaction) SignerAddress() (crypto.Address, error) {
+	if len(tx.Signature) == 0 {
+		return crypto.Address{}, TxMissingSignature
+	}
+
+	hash := tx.Hash(TxHasher{})
+	address, err := crypto.SignerAddress(tx.Scheme, tx.SignerKey, hash, tx.Signature)
+	if err != nil {
+		return crypto.Address{}, fmt.Errorf("Tx [%s] signature verification failed.", hash.String())
+	}
+
+	return address, nil
+}
+
+// Bytes returns the byte slice representation of the Transaction including its Signature.
+// The fields are written in a fixed order using little-endian encoding and the variable
+// length fields are prefixed with their length. A carried SignerKey follows the Signature
+// along with the Scheme.
+func (tx *Transaction) Bytes() []byte {
+	return tx.appendBytes(make([]byte, 0, tx.byteSize()))
+}
+
+// byteSize returns the length of the Transaction byte slice representation.
+func (tx *Transaction) byteSize() int {
+	size := crypto.ADDR_BYTE_SIZE + TX_FIXED_BYTE_SIZE + len(tx.Data) + len(tx.Signature)
+	if len(tx.SignerKey) != 0 {
+		size += TX_SIGNER_KEY_FIXED_BYTE_SIZE + len(tx.SignerKey)
+	}
+	return size
+}
+
+// appendBytes appends the byte representation of the Transaction to b.
+func (tx *Transaction) appendBytes(b []byte) []byte {
+	b = tx.appendFixedBytes(b)
+	b = append(b, tx.Data...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(tx.Signature)))
+	b = append(b, tx.Signature...)
+	if len(tx.SignerKey) != 0 {
+		b = append(b, byte(tx.Scheme))
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(tx.SignerKey)))
+		b = append(b, tx.SignerKey...)
+	}
+	return b
+}
+
+// writeBytes writes the byte representation of the Transaction to w.
+// The fixed size fields are staged in the scratch buffer which is returned
+// for reuse, the variable size fields are written to w without being copied.
+func (tx *Transaction) writeBytes(w io.Writer, scratch []byte) ([]byte, error) {
+	scratch = tx.appendFixedBytes(scratch[:0])
+	if _, err := w.Write(scratch); err != nil {
+		return scratch, err
+	}
+
+	if _, err := w.Write(tx.Data); err != nil {
+		return scratch, err
+	}
+
+	scratch = binary.LittleEndian.AppendUint32(scratch[:0], uint32(len(tx.Signature)))
+	if _, err := w.Write(scratch); err != nil {
+		return scratch, err
+	}
+
+	if _, err := w.Write(tx.Signature); err != nil {
+		return scratch, err
+	}
+
+	if len(tx.SignerKey) == 0 {
+		return scratch, nil
+	}
+
+	scratch = append(scratch[:0], byte(tx.Scheme))
+	scratch = binary.LittleEndian.AppendUint32(scratch, uint32(len(tx.SignerKey)))
+	if _, err := w.Write(scratch); err != nil {
+		return scratch, err
+	}
+
+	_, err := w.Write(tx.SignerKey)
+	return scratch, err
+}
+
+// appendFixedBytes appends the byte representation of the Transaction fields
+// preceding the Data bytes to b (up to and including the Data length prefix).
+func (tx *Transaction) appendFixedBytes(b []byte) []byte {
+	b = binary.LittleEndian.AppendUint32(b, tx.ChainID)
+	b = append(b, tx.To[:]...)
+	b = binary.LittleEndian.AppendUint64(b, tx.Value)
+	b = binary.LittleEndian.AppendUint64(b, tx.Fee)
+	b = binary.LittleEndian.AppendUint64(b, tx.Nonce)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(tx.Data)))
+	return b
+}
+
 // Decode the Decoder into the Transaction.
 func (tx *Transaction) Decode(dec Decoder[*Transaction]) error {
 	return dec.Decode(tx)
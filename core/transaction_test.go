@@ -71,9 +71,117 @@ func TestTxEncodeDecode(t *testing.T) {
 	assert.Equal(t, tx, txDecoded)
 }
 
+func TestTxJSONDecodeEncode(t *testing.T) {
+	fromPrivKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	// Generate a Tx and sign it.
+	tx := genTxWithoutSignature(t)
+	assert.Nil(t, tx.Sign(fromPrivKey))
+	tx.hash = crypto.Hash{}
+
+	// Encode the Tx and check that the Address is a hexadecimal string.
+	txEncoded := &bytes.Buffer{}
+	assert.Nil(t, tx.Encode(NewJSONTxEncoder(txEncoded)))
+	assert.Contains(t, txEncoded.String(), `"`+tx.To.String()+`"`)
+
+	// Decode the encoded Tx and compare it with the original Tx.
+	txDecoded := new(Transaction)
+	assert.Nil(t, txDecoded.Decode(NewJSONTxDecoder(txEncoded)))
+	assert.Equal(t, tx, txDecoded)
+
+	// Check that an unsigned Tx keeps its nil Signature.
+	unsigned := genTxWithoutSignature(t)
+	txEncoded.Reset()
+	assert.Nil(t, unsigned.Encode(NewJSONTxEncoder(txEncoded)))
+	txDecoded = new(Transaction)
+	assert.Nil(t, txDecoded.Decode(NewJSONTxDecoder(txEncoded)))
+	assert.Nil(t, txDecoded.Signature)
+}
+
+func TestTxBytesIncludesSignature(t *testing.T) {
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+	otherPrivKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	// Generate a Tx and sign it.
+	tx := genTxWithoutSignature(t)
+	assert.Nil(t, tx.Sign(privKey))
+	signedBytes := tx.Bytes()
+	dataHash, err := ComputeDataHash([]*Transaction{tx})
+	assert.Nil(t, err)
+
+	// Sign the same Tx with another key.
+	assert.Nil(t, tx.Sign(otherPrivKey))
+
+	// Check that the byte representation and the DataHash commit to the Signature.
+	assert.NotEqual(t, signedBytes, tx.Bytes())
+	otherDataHash, err := ComputeDataHash([]*Transaction{tx})
+	assert.Nil(t, err)
+	assert.NotEqual(t, dataHash, otherDataHash)
+}
+
+func TestTransactionEd25519Signer(t *testing.T) {
+	signer, err := crypto.GenerateEd25519PrivateKey()
+	assert.Nil(t, err)
+
+	// Generate a Tx and sign it with Ed25519.
+	tx := genTxWithoutSignature(t)
+	assert.Nil(t, tx.Sign(signer))
+
+	// Check that the PublicKey is carried since Ed25519 has no PublicKey recovery.
+	assert.Equal(t, crypto.SchemeEd25519, tx.Scheme)
+	assert.Equal(t, signer.PublicKey(), tx.SignerKey)
+	txSigner, err := tx.Signer()
+	assert.Nil(t, err)
+	assert.Equal(t, signer.PublicKey().Address(), txSigner.Address())
+
+	// Check that the Signature is rejected for another carried PublicKey.
+	otherSigner, err := crypto.GenerateEd25519PrivateKey()
+	assert.Nil(t, err)
+	tx.SignerKey = otherSigner.PublicKey()
+	_, err = tx.Signer()
+	assert.NotNil(t, err)
+
+	// Check that the Signature is rejected once the Tx is tampered with.
+	tx.SignerKey = signer.PublicKey()
+	tx.Value++
+	tx.InvalidateHash()
+	_, err = tx.Signer()
+	assert.NotNil(t, err)
+
+	// Check that signing again with secp256k1 drops the carried PublicKey.
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Sign(privKey))
+	assert.Nil(t, tx.SignerKey)
+	txSigner, err = tx.Signer()
+	assert.Nil(t, err)
+	assert.Equal(t, privKey.PublicKey().Address(), txSigner.Address())
+}
+
+func TestTransactionSignerAddress(t *testing.T) {
+	// Check that an unsigned Tx has no signer Address.
+	tx := genTxWithoutSignature(t)
+	_, err := tx.SignerAddress()
+	assert.ErrorIs(t, err, TxMissingSignature)
+
+	for _, scheme := range []crypto.SignatureScheme{crypto.SchemeSecp256k1, crypto.SchemeEd25519} {
+		signer, err := crypto.GenerateSigner(scheme)
+		assert.Nil(t, err)
+		assert.Nil(t, tx.Sign(signer))
+
+		// Check that the signer Address matches the signer PublicKey Address.
+		address, err := tx.SignerAddress()
+		assert.Nil(t, err)
+		assert.Equal(t, signer.PublicKey().Address(), address, scheme.String())
+	}
+}
+
 func genTxWithoutSignature(t *testing.T) *Transaction {
 	toPrivKey, err := crypto.GeneratePrivateKey()
 	assert.Nil(t, err)
-	tx := NewTransaction([]byte("foo"), toPrivKey.PublicKey().Address(), 42)
+	tx := NewTransaction([]byte("foo"), toPrivKey.PublicKey().Address(), 42, 1, 0, 0)
 	return tx
 }
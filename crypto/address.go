@@ -3,6 +3,8 @@ package crypto
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"golang.org/x/crypto/blake2b"
@@ -11,6 +13,10 @@ import (
 // ADDR_BYTE_SIZE is the length of the Address in bytes
 const ADDR_BYTE_SIZE = 32
 
+var (
+	InvalidAddressChecksum = errors.New("The address letters case does not match its checksum.")
+)
+
 // An Address is used to publicly identify a Blockchain account
 type Address [ADDR_BYTE_SIZE]uint8
 
@@ -34,6 +40,64 @@ func (addr Address) String() string {
 	return hex.EncodeToString(addr.Bytes())
 }
 
+// Checksum returns the EIP-55 style mixed-case checksummed hexadecimal string
+// representation of the Address. Each hexadecimal letter is uppercased when the
+// matching nibble of the blake2b Hash of the lowercase representation is >= 8.
+func (addr Address) Checksum() string {
+	lower := []byte(addr.String())
+	hash := blake2b.Sum256(lower)
+
+	for i, c := range lower {
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+
+		if c >= 'a' && c <= 'f' && nibble >= 8 {
+			lower[i] = c - 'a' + 'A'
+		}
+	}
+
+	return string(lower)
+}
+
+// MarshalJSON returns the hexadecimal representation of the Address
+// as a JSON string.
+func (addr Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(addr.String())
+}
+
+// UnmarshalJSON sets the Address from its hexadecimal JSON string representation.
+func (addr *Address) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	a, err := AddressFromString(text)
+	if err != nil {
+		return err
+	}
+
+	*addr = a
+	return nil
+}
+
+// AddressFromChecksummed returns an Address given its checksummed hexadecimal string
+// representation and fails if the letters case does not match the checksum.
+func AddressFromChecksummed(checksummed string) (Address, error) {
+	addr, err := AddressFromString(checksummed)
+	if err != nil {
+		return Address{}, err
+	}
+
+	if addr.Checksum() != checksummed {
+		return Address{}, InvalidAddressChecksum
+	}
+
+	return addr, nil
+}
+
 // AddressFromString returns an Address given a (HASH_BYTE_SIZE * 8) bits hexadecimal address string.
 func AddressFromString(hexAddress string) (Address, error) {
 	b, err := hex.DecodeString(hexAddress)
@@ -50,7 +114,7 @@ func AddressFromBytes(b []byte) (Address, error) {
 	}
 
 	var uints [ADDR_BYTE_SIZE]uint8
-	for i := 0; i < HASH_BYTE_SIZE; i++ {
+	for i := 0; i < ADDR_BYTE_SIZE; i++ {
 		uints[i] = b[i]
 	}
 
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,3 +23,60 @@ func TestAddressIsOwner(t *testing.T) {
 	assert.True(t, address.IsOwner(pubKey))
 	assert.False(t, address.IsOwner(otherPubKey))
 }
+
+func TestAddressChecksum(t *testing.T) {
+	privKey, err := GeneratePrivateKey()
+	assert.Nil(t, err)
+	address := privKey.PublicKey().Address()
+
+	// Check that the checksummed Address decodes to the Address.
+	checksummed := address.Checksum()
+	assert.Equal(t, address.String(), strings.ToLower(checksummed))
+	decoded, err := AddressFromChecksummed(checksummed)
+	assert.Nil(t, err)
+	assert.Equal(t, address, decoded)
+
+	// Flip the case of the first letter and check the checksum is rejected.
+	flipped := []byte(checksummed)
+	for i, c := range flipped {
+		if c >= 'a' && c <= 'f' {
+			flipped[i] = c - 'a' + 'A'
+			break
+		}
+		if c >= 'A' && c <= 'F' {
+			flipped[i] = c - 'A' + 'a'
+			break
+		}
+	}
+	_, err = AddressFromChecksummed(string(flipped))
+	assert.Equal(t, InvalidAddressChecksum, err)
+
+	// Check that malformed checksummed Addresses are rejected.
+	_, err = AddressFromChecksummed(checksummed[:len(checksummed)-2])
+	assert.NotNil(t, err)
+	_, err = AddressFromChecksummed("z" + checksummed[1:])
+	assert.NotNil(t, err)
+
+	// Check that the plain String representation is unchanged.
+	fromString, err := AddressFromString(address.String())
+	assert.Nil(t, err)
+	assert.Equal(t, address, fromString)
+}
+
+func TestAddressFromBytes(t *testing.T) {
+	b := make([]byte, ADDR_BYTE_SIZE)
+	for i := range b {
+		b[i] = byte(i + 1)
+	}
+
+	// Check that every byte survives the round trip.
+	addr, err := AddressFromBytes(b)
+	assert.Nil(t, err)
+	assert.Equal(t, b, addr[:])
+
+	// Check that a byte slice with the wrong length is rejected.
+	_, err = AddressFromBytes(b[:ADDR_BYTE_SIZE-1])
+	assert.NotNil(t, err)
+	_, err = AddressFromBytes(append(b, 0x00))
+	assert.NotNil(t, err)
+}
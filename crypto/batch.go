@@ -0,0 +1,29 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	BatchLengthMismatch = errors.New("The batch signatures, hashes and public keys counts do not match.")
+	InvalidSignature    = errors.New("The signature does not match the hash and public key.")
+)
+
+// VerifyBatch checks that every secp256k1 Signature of the batch was produced
+// over the Hash at the same index by the private key of the PublicKey at the same index.
+// The verification stops at the first invalid Signature and the returned error reports its index.
+func VerifyBatch(sigs []Signature, hashes []Hash, pubKeys []PublicKey) error {
+	if len(sigs) != len(hashes) || len(sigs) != len(pubKeys) {
+		return fmt.Errorf("%w (%d signatures, %d hashes, %d public keys)", BatchLengthMismatch, len(sigs), len(hashes), len(pubKeys))
+	}
+
+	verifier := Secp256k1Verifier{}
+	for i := range sigs {
+		if !verifier.Verify(pubKeys[i], hashes[i], sigs[i]) {
+			return fmt.Errorf("Batch verification failed at index %d: %w", i, InvalidSignature)
+		}
+	}
+
+	return nil
+}
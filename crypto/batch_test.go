@@ -0,0 +1,45 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// genBatch returns n secp256k1 Signatures of distinct Hashes along with the signers PublicKeys.
+func genBatch(t *testing.T, n int) ([]Signature, []Hash, []PublicKey) {
+	sigs := make([]Signature, n)
+	hashes := make([]Hash, n)
+	pubKeys := make([]PublicKey, n)
+
+	for i := 0; i < n; i++ {
+		privKey, err := GeneratePrivateKey()
+		assert.Nil(t, err)
+
+		hashes[i] = Hash{byte(i + 1)}
+		sigs[i], err = privKey.Sign(hashes[i])
+		assert.Nil(t, err)
+		pubKeys[i] = privKey.PublicKey()
+	}
+
+	return sigs, hashes, pubKeys
+}
+
+func TestVerifyBatch(t *testing.T) {
+	sigs, hashes, pubKeys := genBatch(t, 4)
+
+	// Check that a batch of valid Signatures is verified.
+	assert.Nil(t, VerifyBatch(sigs, hashes, pubKeys))
+	assert.Nil(t, VerifyBatch(nil, nil, nil))
+
+	// Check that a Signature over another Hash is rejected and its index reported.
+	hashes[2], hashes[3] = hashes[3], hashes[2]
+	err := VerifyBatch(sigs, hashes, pubKeys)
+	assert.True(t, errors.Is(err, InvalidSignature))
+	assert.Contains(t, err.Error(), "index 2")
+
+	// Check that a batch with mismatching lengths is rejected.
+	err = VerifyBatch(sigs, hashes[:3], pubKeys)
+	assert.True(t, errors.Is(err, BatchLengthMismatch))
+}
@@ -3,6 +3,7 @@ package crypto
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 )
 
@@ -36,6 +37,28 @@ func (h Hash) String() string {
 	return hex.EncodeToString(h.Bytes())
 }
 
+// MarshalJSON returns the hexadecimal representation of the Hash
+// as a JSON string.
+func (h Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+// UnmarshalJSON sets the Hash from its hexadecimal JSON string representation.
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	hash, err := HashFromString(text)
+	if err != nil {
+		return err
+	}
+
+	*h = hash
+	return nil
+}
+
 // HashFromString returns a Hash given a (HASH_BYTE_SIZE * 8) bits hexadecimal hash string.
 func HashFromString(hstr string) (Hash, error) {
 	hbyt, err := hex.DecodeString(hstr)
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,3 +23,22 @@ func TestHashConversion(t *testing.T) {
 	assert.Equal(t, hashFromStr.String(), hashFromByt.String())
 	assert.Equal(t, HASH_LEGIT, hashFromByt.String())
 }
+
+func TestHashJSON(t *testing.T) {
+	hash, err := HashFromString(HASH_LEGIT)
+	assert.Nil(t, err)
+
+	// Check that the Hash is encoded as a hexadecimal JSON string.
+	b, err := json.Marshal(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, `"`+HASH_LEGIT+`"`, string(b))
+
+	// Check that the decoded Hash matches the original Hash.
+	var decoded Hash
+	assert.Nil(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, hash, decoded)
+
+	// Check that an invalid hexadecimal string or length is rejected.
+	assert.NotNil(t, json.Unmarshal([]byte(`"zz"`), &decoded))
+	assert.NotNil(t, json.Unmarshal([]byte(`"d02b"`), &decoded))
+}
@@ -1,17 +1,25 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log"
+	"os"
+	"strings"
 
 	"golang.org/x/crypto/blake2b"
 
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// PRIV_KEY_BYTE_SIZE is the length in bytes of the PrivateKey secret scalar.
+const PRIV_KEY_BYTE_SIZE = 32
+
 // A PrivateKey is used for signing objects.
 type PrivateKey struct {
 	key *ecdsa.PrivateKey
@@ -27,11 +35,46 @@ func (k PrivateKey) Sign(hash Hash) (Signature, error) {
 	return Signature(sig), nil
 }
 
-// NewPrivateKeyFromReader returns a random PrivateKey from a io.Reader entropy.
+// NewPrivateKeyFromReader returns a PrivateKey whose secret scalar is read from the io.Reader entropy.
+// The same entropy always gives the same PrivateKey, the 32 bytes candidates that are not a
+// valid secp256k1 scalar (zero or above the curve order) are skipped.
 func NewPrivateKeyFromReader(r io.Reader) (PrivateKey, error) {
-	key, err := crypto.GenerateKey()
+	b := make([]byte, PRIV_KEY_BYTE_SIZE)
+	for {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return PrivateKey{}, err
+		}
+
+		key, err := crypto.ToECDSA(b)
+		if err == nil {
+			return PrivateKey{
+				key: key,
+			}, nil
+		}
+	}
+}
+
+// GeneratePrivateKey returns a PrivateKey randomized using cryptographically secure entropy.
+func GeneratePrivateKey() (PrivateKey, error) {
+	return NewPrivateKeyFromReader(rand.Reader)
+}
+
+// Bytes returns the 32 bytes big-endian secret scalar of the PrivateKey.
+func (k PrivateKey) Bytes() []byte {
+	return crypto.FromECDSA(k.key)
+}
+
+// Hex returns the hexadecimal string encoding of the PrivateKey.
+// It is not named String so the secret is never printed by accident.
+func (k PrivateKey) Hex() string {
+	return hex.EncodeToString(k.Bytes())
+}
+
+// PrivateKeyFromBytes returns a PrivateKey given its 32 bytes secret scalar.
+func PrivateKeyFromBytes(b []byte) (PrivateKey, error) {
+	key, err := crypto.ToECDSA(b)
 	if err != nil {
-		return PrivateKey{}, err
+		return PrivateKey{}, fmt.Errorf("Invalid private key bytes: %w", err)
 	}
 
 	return PrivateKey{
@@ -39,9 +82,30 @@ func NewPrivateKeyFromReader(r io.Reader) (PrivateKey, error) {
 	}, nil
 }
 
-// GeneratePrivateKey returns a PrivateKey randomized using cryptographically secure entropy.
-func GeneratePrivateKey() (PrivateKey, error) {
-	return NewPrivateKeyFromReader(rand.Reader)
+// PrivateKeyFromHex returns a PrivateKey given the hexadecimal string encoding of its secret scalar.
+func PrivateKeyFromHex(hexKey string) (PrivateKey, error) {
+	b, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return PrivateKey{}, fmt.Errorf("Invalid private key hex string: %w", err)
+	}
+
+	return PrivateKeyFromBytes(b)
+}
+
+// SaveToFile writes the hexadecimal string encoding of the PrivateKey in a file
+// readable and writable by the owner only.
+func (k PrivateKey) SaveToFile(path string) error {
+	return os.WriteFile(path, []byte(k.Hex()), 0o600)
+}
+
+// LoadPrivateKey reads a PrivateKey saved in a file by SaveToFile.
+func LoadPrivateKey(path string) (PrivateKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+
+	return PrivateKeyFromHex(strings.TrimSpace(string(b)))
 }
 
 // PublicKey returns the PublicKey of the PrivateKey.
@@ -64,6 +128,28 @@ func (k PublicKey) String() string {
 	return hex.EncodeToString(k)
 }
 
+// MarshalJSON returns the hexadecimal representation of the PublicKey
+// as a JSON string.
+func (k PublicKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+// UnmarshalJSON sets the PublicKey from its hexadecimal JSON string representation.
+func (k *PublicKey) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	b, err := decodeHexText(text)
+	if err != nil {
+		return err
+	}
+
+	*k = b
+	return nil
+}
+
 // Address returns the public Address corresponding to the PublicKey
 func (k PublicKey) Address() Address {
 	h := Hash(blake2b.Sum256(k))
@@ -83,7 +169,60 @@ func (sig Signature) PublicKey(hash Hash) (PublicKey, error) {
 	return PublicKey(pubKey), nil
 }
 
+// SignerAddress returns the Address of the Signature signer.
+func (sig Signature) SignerAddress(hash Hash) (Address, error) {
+	pubKey, err := sig.PublicKey(hash)
+	if err != nil {
+		return Address{}, err
+	}
+
+	return pubKey.Address(), nil
+}
+
+// Equal checks that the Signature is byte-for-byte equal to another Signature.
+func (sig Signature) Equal(other Signature) bool {
+	return bytes.Equal(sig, other)
+}
+
+// Compare returns an integer comparing the Signature bytes lexicographically
+// to another Signature. The result is 0 if sig == other, -1 if sig < other and +1 if sig > other.
+func (sig Signature) Compare(other Signature) int {
+	return bytes.Compare(sig, other)
+}
+
 // String returns a hexadecimal string encoding of the Signature.
 func (sig Signature) String() string {
 	return hex.EncodeToString(sig)
 }
+
+// MarshalJSON returns the hexadecimal representation of the Signature
+// as a JSON string.
+func (sig Signature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sig.String())
+}
+
+// UnmarshalJSON sets the Signature from its hexadecimal JSON string representation.
+func (sig *Signature) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	b, err := decodeHexText(text)
+	if err != nil {
+		return err
+	}
+
+	*sig = b
+	return nil
+}
+
+// decodeHexText decodes a hexadecimal text into a byte slice.
+// An empty text is decoded as a nil byte slice so unset values survive a round trip.
+func decodeHexText(text string) ([]byte, error) {
+	if len(text) == 0 {
+		return nil, nil
+	}
+
+	return hex.DecodeString(text)
+}
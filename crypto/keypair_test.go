@@ -2,6 +2,8 @@ package crypto
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -56,6 +58,98 @@ func TestSignRecoverublicKeyTampered(t *testing.T) {
 	assert.True(t, bytes.Equal(sigPubKey, pubKey))
 }
 
+func TestSignatureSignerAddress(t *testing.T) {
+	privKey, err := GeneratePrivateKey()
+	assert.Nil(t, err)
+	hash, err := HashFromString(HASH_LEGIT)
+	assert.Nil(t, err)
+
+	// Sign a Hash
+	sig, err := privKey.Sign(hash)
+	assert.Nil(t, err)
+
+	// Check that the signer Address matches the one of the recovered PublicKey
+	sigPubKey, err := sig.PublicKey(hash)
+	assert.Nil(t, err)
+	sigAddress, err := sig.SignerAddress(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, sigPubKey.Address(), sigAddress)
+	assert.Equal(t, privKey.PublicKey().Address(), sigAddress)
+
+	// Check that a malformed Signature fails like the PublicKey recovery
+	malformedSig := sig[:len(sig)-1]
+	_, pubKeyErr := malformedSig.PublicKey(hash)
+	assert.NotNil(t, pubKeyErr)
+	_, addrErr := malformedSig.SignerAddress(hash)
+	assert.Equal(t, pubKeyErr, addrErr)
+}
+
+func TestSignatureEqualCompare(t *testing.T) {
+	low := Signature{0x01, 0x02, 0x03}
+	lowCopy := Signature{0x01, 0x02, 0x03}
+	high := Signature{0x01, 0x02, 0x04}
+	short := Signature{0x01, 0x02}
+
+	// Check equality of identical and different Signatures
+	assert.True(t, low.Equal(lowCopy))
+	assert.False(t, low.Equal(high))
+	assert.False(t, low.Equal(short))
+
+	// Check the lexicographic ordering of Signatures
+	assert.Equal(t, 0, low.Compare(lowCopy))
+	assert.Equal(t, -1, low.Compare(high))
+	assert.Equal(t, 1, high.Compare(low))
+	assert.Equal(t, 1, low.Compare(short))
+}
+
+func TestPrivateKeySerialization(t *testing.T) {
+	privKey, err := GeneratePrivateKey()
+	assert.Nil(t, err)
+
+	// Round trip the PrivateKey through bytes and hex
+	fromBytes, err := PrivateKeyFromBytes(privKey.Bytes())
+	assert.Nil(t, err)
+	fromHex, err := PrivateKeyFromHex(privKey.Hex())
+	assert.Nil(t, err)
+
+	// Check that the restored PrivateKeys have the same PublicKey and Address
+	for _, restored := range []PrivateKey{fromBytes, fromHex} {
+		assert.Equal(t, privKey.PublicKey(), restored.PublicKey())
+		assert.Equal(t, privKey.PublicKey().Address(), restored.PublicKey().Address())
+	}
+
+	// Check that malformed inputs are rejected
+	_, err = PrivateKeyFromBytes([]byte{0x01, 0x02})
+	assert.NotNil(t, err)
+	_, err = PrivateKeyFromHex("not hex")
+	assert.NotNil(t, err)
+}
+
+func TestPrivateKeyFile(t *testing.T) {
+	privKey, err := GeneratePrivateKey()
+	assert.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "node.key")
+
+	// Save the PrivateKey and check the file is only accessible by its owner
+	assert.Nil(t, privKey.SaveToFile(path))
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Load the PrivateKey and check it matches the saved one
+	loaded, err := LoadPrivateKey(path)
+	assert.Nil(t, err)
+	assert.Equal(t, privKey.PublicKey().Address(), loaded.PublicKey().Address())
+
+	// Check that loading a malformed or missing file returns an error
+	malformedPath := filepath.Join(t.TempDir(), "malformed.key")
+	assert.Nil(t, os.WriteFile(malformedPath, []byte("malformed"), 0o600))
+	_, err = LoadPrivateKey(malformedPath)
+	assert.ErrorContains(t, err, "Invalid private key")
+	_, err = LoadPrivateKey(filepath.Join(t.TempDir(), "missing.key"))
+	assert.NotNil(t, err)
+}
+
 func BenchmarkPublicKeyRecover(b *testing.B) {
 	privKey, _ := GeneratePrivateKey()
 	hash, _ := HashFromString(HASH_LEGIT)
@@ -66,3 +160,24 @@ func BenchmarkPublicKeyRecover(b *testing.B) {
 		_, _ = sig.PublicKey(hash)
 	}
 }
+
+func TestPrivateKeyFromReader(t *testing.T) {
+	entropy := bytes.Repeat([]byte{0x42}, 2*PRIV_KEY_BYTE_SIZE)
+
+	// Check that the same entropy gives the same PrivateKey.
+	privKey, err := NewPrivateKeyFromReader(bytes.NewReader(entropy))
+	assert.Nil(t, err)
+	sameKey, err := NewPrivateKeyFromReader(bytes.NewReader(entropy))
+	assert.Nil(t, err)
+	assert.Equal(t, entropy[:PRIV_KEY_BYTE_SIZE], privKey.Bytes())
+	assert.Equal(t, privKey.Bytes(), sameKey.Bytes())
+
+	// Check that an invalid scalar candidate is skipped.
+	privKey, err = NewPrivateKeyFromReader(bytes.NewReader(append(make([]byte, PRIV_KEY_BYTE_SIZE), entropy...)))
+	assert.Nil(t, err)
+	assert.Equal(t, entropy[:PRIV_KEY_BYTE_SIZE], privKey.Bytes())
+
+	// Check that exhausted entropy is reported.
+	_, err = NewPrivateKeyFromReader(bytes.NewReader(entropy[:PRIV_KEY_BYTE_SIZE-1]))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,174 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+)
+
+// A SignatureScheme identifies the algorithm used to produce a Signature.
+type SignatureScheme byte
+
+const (
+	SchemeSecp256k1 SignatureScheme = 0x1 // ECDSA over secp256k1 with PublicKey recovery
+	SchemeEd25519   SignatureScheme = 0x2 // Ed25519 without PublicKey recovery
+)
+
+// String returns the name of the SignatureScheme.
+func (s SignatureScheme) String() string {
+	switch s {
+	case SchemeSecp256k1:
+		return "secp256k1"
+	case SchemeEd25519:
+		return "ed25519"
+	default:
+		return "unknown"
+	}
+}
+
+// Recoverable reports whether the signer PublicKey can be recovered from a Signature
+// of the SignatureScheme. An unset SignatureScheme is handled as SchemeSecp256k1.
+func (s SignatureScheme) Recoverable() bool {
+	return s == 0 || s == SchemeSecp256k1
+}
+
+// The Signer interface exposes the methods of a private key used for signing Hashes.
+type Signer interface {
+	Sign(Hash) (Signature, error)
+	PublicKey() PublicKey
+	Scheme() SignatureScheme
+}
+
+// The Verifier interface exposes the method checking that a Signature
+// of a Hash was produced by the private key matching a PublicKey.
+type Verifier interface {
+	Verify(PublicKey, Hash, Signature) bool
+}
+
+// GenerateSigner returns a Signer of the SignatureScheme randomized using
+// cryptographically secure entropy.
+func GenerateSigner(scheme SignatureScheme) (Signer, error) {
+	switch scheme {
+	case SchemeSecp256k1:
+		return GeneratePrivateKey()
+	case SchemeEd25519:
+		return GenerateEd25519PrivateKey()
+	default:
+		return nil, fmt.Errorf("Signature scheme %d is not supported.", scheme)
+	}
+}
+
+// NewVerifier returns the Verifier of the SignatureScheme.
+func NewVerifier(scheme SignatureScheme) (Verifier, error) {
+	switch scheme {
+	case SchemeSecp256k1:
+		return Secp256k1Verifier{}, nil
+	case SchemeEd25519:
+		return Ed25519Verifier{}, nil
+	default:
+		return nil, fmt.Errorf("Signature scheme %d is not supported.", scheme)
+	}
+}
+
+// SignerPublicKey returns the PublicKey of the signer of a Signature over the Hash.
+// For a Recoverable SignatureScheme the PublicKey is recovered from the Signature and
+// pubKey must be empty, otherwise the Signature is verified against the carried pubKey.
+func SignerPublicKey(scheme SignatureScheme, pubKey PublicKey, hash Hash, sig Signature) (PublicKey, error) {
+	if scheme.Recoverable() {
+		if len(pubKey) != 0 {
+			return nil, fmt.Errorf("Signature scheme %s does not carry a public key.", scheme)
+		}
+		return sig.PublicKey(hash)
+	}
+
+	verifier, err := NewVerifier(scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	if !verifier.Verify(pubKey, hash, sig) {
+		return nil, InvalidSignature
+	}
+
+	return pubKey, nil
+}
+
+// SignerAddress returns the Address of the signer of a Signature over the Hash.
+// The SignatureScheme and the carried pubKey are handled as in SignerPublicKey.
+func SignerAddress(scheme SignatureScheme, pubKey PublicKey, hash Hash, sig Signature) (Address, error) {
+	if scheme.Recoverable() && len(pubKey) == 0 {
+		return sig.SignerAddress(hash)
+	}
+
+	signer, err := SignerPublicKey(scheme, pubKey, hash, sig)
+	if err != nil {
+		return Address{}, err
+	}
+
+	return signer.Address(), nil
+}
+
+// Scheme returns the SignatureScheme of the PrivateKey.
+func (k PrivateKey) Scheme() SignatureScheme {
+	return SchemeSecp256k1
+}
+
+// Secp256k1Verifier implements the Verifier interface for secp256k1 Signatures.
+type Secp256k1Verifier struct{}
+
+// Verify recovers the Signature signer PublicKey and compares it to the PublicKey.
+func (Secp256k1Verifier) Verify(pubKey PublicKey, hash Hash, sig Signature) bool {
+	sigPubKey, err := sig.PublicKey(hash)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(sigPubKey, pubKey)
+}
+
+// An Ed25519PrivateKey is used for signing objects with Ed25519.
+// Ed25519 Signatures do not allow PublicKey recovery, the PublicKey
+// has to be carried along the Signature to verify it.
+type Ed25519PrivateKey struct {
+	key ed25519.PrivateKey
+}
+
+// GenerateEd25519PrivateKey returns an Ed25519PrivateKey randomized using cryptographically secure entropy.
+func GenerateEd25519PrivateKey() (Ed25519PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Ed25519PrivateKey{}, err
+	}
+
+	return Ed25519PrivateKey{
+		key: key,
+	}, nil
+}
+
+// Sign returns the Ed25519 Signature of a Hash.
+func (k Ed25519PrivateKey) Sign(hash Hash) (Signature, error) {
+	return Signature(ed25519.Sign(k.key, hash.Bytes())), nil
+}
+
+// PublicKey returns the PublicKey of the Ed25519PrivateKey.
+func (k Ed25519PrivateKey) PublicKey() PublicKey {
+	return PublicKey(k.key.Public().(ed25519.PublicKey))
+}
+
+// Scheme returns the SignatureScheme of the Ed25519PrivateKey.
+func (k Ed25519PrivateKey) Scheme() SignatureScheme {
+	return SchemeEd25519
+}
+
+// Ed25519Verifier implements the Verifier interface for Ed25519 Signatures.
+type Ed25519Verifier struct{}
+
+// Verify checks the Ed25519 Signature of the Hash against the PublicKey.
+func (Ed25519Verifier) Verify(pubKey PublicKey, hash Hash, sig Signature) bool {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return false
+	}
+
+	return ed25519.Verify(ed25519.PublicKey(pubKey), hash.Bytes(), sig)
+}
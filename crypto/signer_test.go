@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignerVerify(t *testing.T) {
+	hash, err := HashFromString(HASH_LEGIT)
+	assert.Nil(t, err)
+	tamperedHash, err := HashFromString(HASH_TAMPERED)
+	assert.Nil(t, err)
+
+	for _, scheme := range []SignatureScheme{SchemeSecp256k1, SchemeEd25519} {
+		// Generate a Signer of the scheme and sign a Hash
+		signer, err := GenerateSigner(scheme)
+		assert.Nil(t, err)
+		assert.Equal(t, scheme, signer.Scheme())
+		sig, err := signer.Sign(hash)
+		assert.Nil(t, err)
+
+		verifier, err := NewVerifier(scheme)
+		assert.Nil(t, err)
+
+		// Check that the Signature is verified against the signer PublicKey only
+		assert.True(t, verifier.Verify(signer.PublicKey(), hash, sig), scheme.String())
+		assert.False(t, verifier.Verify(signer.PublicKey(), tamperedHash, sig), scheme.String())
+
+		otherSigner, err := GenerateSigner(scheme)
+		assert.Nil(t, err)
+		assert.False(t, verifier.Verify(otherSigner.PublicKey(), hash, sig), scheme.String())
+	}
+}
+
+func TestSignerCrossScheme(t *testing.T) {
+	hash, err := HashFromString(HASH_LEGIT)
+	assert.Nil(t, err)
+
+	// Sign a Hash with Ed25519
+	signer, err := GenerateEd25519PrivateKey()
+	assert.Nil(t, err)
+	sig, err := signer.Sign(hash)
+	assert.Nil(t, err)
+
+	// Check that the Signature is not accepted by the secp256k1 Verifier
+	assert.False(t, Secp256k1Verifier{}.Verify(signer.PublicKey(), hash, sig))
+
+	// Check that unsupported schemes are rejected
+	_, err = GenerateSigner(SignatureScheme(0))
+	assert.NotNil(t, err)
+	_, err = NewVerifier(SignatureScheme(0))
+	assert.NotNil(t, err)
+}
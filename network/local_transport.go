@@ -2,6 +2,7 @@ package network
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net"
 	"sync"
@@ -44,7 +45,15 @@ func (tr *LocalTransport) Connect(peerTr Transport) error {
 }
 
 // SendMessage sends a payload to a connected peer in a RPC.
+// On success the RPC is only queued in the peer receive channel, the peer may
+// never consume it. SendMessage blocks while the peer receive channel is full.
 func (tr *LocalTransport) SendMessage(to net.Addr, payload []byte) error {
+	return tr.sendMessage(context.Background(), to, payload)
+}
+
+// sendMessage sends a payload to a connected peer in a RPC and gives up
+// when the context is done before the peer receive channel accepts it.
+func (tr *LocalTransport) sendMessage(ctx context.Context, to net.Addr, payload []byte) error {
 	tr.lock.RLock()
 	defer tr.lock.RUnlock()
 
@@ -57,21 +66,52 @@ func (tr *LocalTransport) SendMessage(to net.Addr, payload []byte) error {
 		return fmt.Errorf("Transport %s on %s network could not find peer %s.", tr.Addr().String(), tr.Addr().Network(), to)
 	}
 
-	peerTr.rpcCh <- RPC{
+	rpc := RPC{
 		From:    tr.addr,
 		Payload: bytes.NewReader(payload),
 	}
 
-	return nil
+	select {
+	case peerTr.rpcCh <- rpc:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-// Broadcast sends a payload in a RPC to all the connected peers.
-func (tr *LocalTransport) Broadcast(payload []byte) error {
-	for _, peer := range tr.peers {
-		if err := tr.SendMessage(peer.Addr(), payload); err != nil {
-			return err
+// Broadcast sends a payload in a RPC to all the connected peers concurrently.
+// A peer that can not be reached before the context is done does not prevent
+// the delivery to the other peers, the failures are gathered in a BroadcastError.
+func (tr *LocalTransport) Broadcast(ctx context.Context, payload []byte) error {
+	tr.lock.RLock()
+	peers := make([]net.Addr, 0, len(tr.peers))
+	for addr := range tr.peers {
+		peers = append(peers, addr)
+	}
+	tr.lock.RUnlock()
+
+	errs := make([]error, len(peers))
+	wg := sync.WaitGroup{}
+	for i, addr := range peers {
+		wg.Add(1)
+		go func(i int, addr net.Addr) {
+			defer wg.Done()
+			errs[i] = tr.sendMessage(ctx, addr, payload)
+		}(i, addr)
+	}
+	wg.Wait()
+
+	broadcastErr := &BroadcastError{Errors: make(map[net.Addr]error)}
+	for i, err := range errs {
+		if err != nil {
+			broadcastErr.Errors[peers[i]] = err
 		}
 	}
+
+	if len(broadcastErr.Errors) != 0 {
+		return broadcastErr
+	}
+
 	return nil
 }
 
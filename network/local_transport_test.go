@@ -2,8 +2,10 @@ package network
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -46,6 +48,25 @@ func TestSendMessage(t *testing.T) {
 	assert.Equal(t, rpc.From, ltra.addr)
 }
 
+func TestSendMessageQueuedOnly(t *testing.T) {
+	aAddr := NetAddr{Addr: "A", Net: "local"}
+	bAddr := NetAddr{Addr: "B", Net: "local"}
+
+	ltra := NewLocalTransport(aAddr)
+	ltrb := NewLocalTransport(bAddr)
+
+	err := ltra.Connect(ltrb)
+	assert.Nil(t, err)
+
+	// Send messages to a peer that never consumes its receive channel.
+	msg := []byte("hello ambula")
+	assert.Nil(t, ltra.SendMessage(ltrb.addr, msg))
+	assert.Nil(t, ltra.SendMessage(ltrb.addr, msg))
+
+	// Check that the successful sends were only queued and never processed.
+	assert.Equal(t, 2, len(ltrb.rpcCh))
+}
+
 func TestBroadcast(t *testing.T) {
 	aAddr := NetAddr{Addr: "A", Net: "local"}
 	bAddr := NetAddr{Addr: "B", Net: "local"}
@@ -62,7 +83,7 @@ func TestBroadcast(t *testing.T) {
 	assert.Nil(t, err)
 
 	msg := []byte("hello ambula")
-	assert.Nil(t, ltra.Broadcast(msg))
+	assert.Nil(t, ltra.Broadcast(context.Background(), msg))
 
 	rpcb := <-ltrb.Consume()
 	b, err := io.ReadAll(rpcb.Payload)
@@ -74,3 +95,41 @@ func TestBroadcast(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, b, msg)
 }
+
+func TestBroadcastFullPeer(t *testing.T) {
+	aAddr := NetAddr{Addr: "A", Net: "local"}
+	bAddr := NetAddr{Addr: "B", Net: "local"}
+	cAddr := NetAddr{Addr: "C", Net: "local"}
+
+	ltra := NewLocalTransport(aAddr)
+	ltrb := NewLocalTransport(bAddr)
+	ltrc := NewLocalTransport(cAddr)
+
+	err := ltra.Connect(ltrb)
+	assert.Nil(t, err)
+
+	err = ltra.Connect(ltrc)
+	assert.Nil(t, err)
+
+	// Fill the C peer receive channel so it can not accept any RPC.
+	for i := 0; i < RPC_CHAN_SIZE; i++ {
+		ltrc.rpcCh <- RPC{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Broadcast and check that only the full peer is reported as failed.
+	msg := []byte("hello ambula")
+	err = ltra.Broadcast(ctx, msg)
+	broadcastErr, ok := err.(*BroadcastError)
+	assert.True(t, ok)
+	assert.Len(t, broadcastErr.Errors, 1)
+	assert.Equal(t, context.DeadlineExceeded, broadcastErr.Errors[cAddr])
+
+	// Check that the other peer still received the payload.
+	rpcb := <-ltrb.Consume()
+	b, err := io.ReadAll(rpcb.Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, b, msg)
+}
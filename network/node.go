@@ -2,9 +2,12 @@
 package network
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,6 +15,35 @@ import (
 // in the Node main loop.
 const TICK_DURATION = 5
 
+var (
+	NodeAlreadyRunning = errors.New("The Node is already running.")
+	NodeAlreadyStopped = errors.New("The Node is already stopped.")
+	NodeCannotRestart  = errors.New("The stopped Node can not be started again.")
+)
+
+// A NodeState represents a step of the Node lifecycle.
+type NodeState byte
+
+const (
+	NodeStateNew     NodeState = iota // Node created but not started
+	NodeStateRunning                  // Node main loop running
+	NodeStateStopped                  // Node stopped, it can not be started again
+)
+
+// String returns the name of the NodeState.
+func (s NodeState) String() string {
+	switch s {
+	case NodeStateNew:
+		return "new"
+	case NodeStateRunning:
+		return "running"
+	case NodeStateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
 // NodeOpts encapsulates the options needed by the Node.
 type NodeOpts struct {
 	Transports []Transport // Transports that will be connected with the Node
@@ -21,7 +53,12 @@ type NodeOpts struct {
 type Node struct {
 	NodeOpts
 	rpcCh  chan RPC      // Channel used for incoming RPC from Transports
-	quitCh chan struct{} // Channel used for Node shutdown event
+	quitCh chan struct{} // Channel closed on Node shutdown event
+	state  NodeState     // Current step of the Node lifecycle
+	lock   sync.Mutex    // Lock guarding the Node state
+
+	workersWg sync.WaitGroup // WaitGroup tracking the Transport workers
+	workers   int32          // Number of running Transport workers
 }
 
 // NewNode instantiates a Node from a NodeOpts.
@@ -29,15 +66,41 @@ func NewNode(opts NodeOpts) *Node {
 	return &Node{
 		NodeOpts: opts,
 		rpcCh:    make(chan RPC),
-		quitCh:   make(chan struct{}, 1),
+		quitCh:   make(chan struct{}),
+		state:    NodeStateNew,
 	}
 }
 
+// State returns the current NodeState of the Node.
+func (n *Node) State() NodeState {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.state
+}
+
 // Start starts the main loop of the Node listening for RPCs from the
 // Transports and passing them to RPC handlers.
+// It blocks until the Node is stopped and all its Transport workers returned.
+// It fails if the Node is not new.
 func (n *Node) Start() error {
+	n.lock.Lock()
+	switch n.state {
+	case NodeStateRunning:
+		n.lock.Unlock()
+		return NodeAlreadyRunning
+	case NodeStateStopped:
+		n.lock.Unlock()
+		return NodeCannotRestart
+	}
+	n.state = NodeStateRunning
+	n.lock.Unlock()
+
 	n.initTransports()
+	defer n.workersWg.Wait()
+
 	ticker := time.NewTicker(TICK_DURATION * time.Second)
+	defer ticker.Stop()
 
 free:
 	for {
@@ -46,6 +109,7 @@ free:
 			buf := new(strings.Builder)
 			_, err := io.Copy(buf, rpc.Payload)
 			if err != nil {
+				_ = n.Stop()
 				return err
 			}
 			fmt.Printf("Peer [%s] sent [%s]\n", rpc.From.String(), buf.String())
@@ -59,13 +123,53 @@ free:
 	return nil
 }
 
+// Stop stops the Node main loop. A Node that was never started can also
+// be stopped, it then can not be started anymore.
+func (n *Node) Stop() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.state == NodeStateStopped {
+		return NodeAlreadyStopped
+	}
+
+	n.state = NodeStateStopped
+	close(n.quitCh)
+
+	return nil
+}
+
+// TransportWorkers returns the number of running Transport worker goroutines.
+func (n *Node) TransportWorkers() int {
+	return int(atomic.LoadInt32(&n.workers))
+}
+
 // initTransports spawns goroutines connecting/listening to Transports
 // and passing RPCs back to the Node on reception.
+// A worker returns when the Node is stopped or its Transport is closed.
 func (n *Node) initTransports() {
 	for _, tr := range n.Transports {
+		n.workersWg.Add(1)
+		atomic.AddInt32(&n.workers, 1)
+
 		go func(tr Transport) {
-			for rpc := range tr.Consume() {
-				n.rpcCh <- rpc
+			defer n.workersWg.Done()
+			defer atomic.AddInt32(&n.workers, -1)
+
+			for {
+				select {
+				case rpc, ok := <-tr.Consume():
+					if !ok {
+						return
+					}
+					select {
+					case n.rpcCh <- rpc:
+					case <-n.quitCh:
+						return
+					}
+				case <-n.quitCh:
+					return
+				}
 			}
 		}(tr)
 	}
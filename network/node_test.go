@@ -0,0 +1,75 @@
+package network
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeLifecycle(t *testing.T) {
+	tr := NewLocalTransport(NetAddr{Addr: "A", Net: "local"})
+	n := NewNode(NodeOpts{Transports: []Transport{tr}})
+	assert.Equal(t, NodeStateNew, n.State())
+
+	// Start the Node main loop and wait for it to be running.
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- n.Start()
+	}()
+	assert.Eventually(t, func() bool { return n.State() == NodeStateRunning }, time.Second, time.Millisecond)
+
+	// Check that starting a running Node fails without side effect.
+	assert.Equal(t, NodeAlreadyRunning, n.Start())
+	assert.Equal(t, NodeStateRunning, n.State())
+
+	// Stop the Node and check that the main loop returned.
+	assert.Nil(t, n.Stop())
+	assert.Nil(t, <-errCh)
+	assert.Equal(t, NodeStateStopped, n.State())
+
+	// Check that stopping twice or restarting a stopped Node fails without panic.
+	assert.Equal(t, NodeAlreadyStopped, n.Stop())
+	assert.Equal(t, NodeCannotRestart, n.Start())
+}
+
+func TestNodeStopBeforeStart(t *testing.T) {
+	n := NewNode(NodeOpts{})
+
+	// Check that a new Node can be stopped and then can not be started.
+	assert.Nil(t, n.Stop())
+	assert.Equal(t, NodeStateStopped, n.State())
+	assert.Equal(t, NodeCannotRestart, n.Start())
+}
+
+func TestNodeTransportWorkers(t *testing.T) {
+	goroutinesBefore := runtime.NumGoroutine()
+
+	// Create a Node with several Transports.
+	transports := []Transport{
+		NewLocalTransport(NetAddr{Addr: "A", Net: "local"}),
+		NewLocalTransport(NetAddr{Addr: "B", Net: "local"}),
+		NewLocalTransport(NetAddr{Addr: "C", Net: "local"}),
+	}
+	n := NewNode(NodeOpts{Transports: transports})
+
+	// Start the Node and check that one worker runs per Transport.
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- n.Start()
+	}()
+	assert.Eventually(t, func() bool { return n.TransportWorkers() == len(transports) }, time.Second, time.Millisecond)
+
+	// Stop the Node and check that all the workers returned.
+	assert.Nil(t, n.Stop())
+	assert.Nil(t, <-errCh)
+	assert.Equal(t, 0, n.TransportWorkers())
+
+	// Check that no goroutine was leaked (polling without spawning new goroutines).
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutinesBefore && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutinesBefore)
+}
@@ -9,12 +9,14 @@ import (
 type MessageType byte
 
 const (
-	MessageTypeTx            MessageType = 0x1 // Transaction MessageType
-	MessageTypeBlock         MessageType = 0x2 // Block MessageType
-	MessageTypeBlockRequest  MessageType = 0x3 // Block Request MessageType
-	MessageTypeStatus        MessageType = 0x4 // Node Status MessageType
-	MessageTypeStatusRequest MessageType = 0x5 // Node Status Request MessageType
-	MessageTypeBlocks        MessageType = 0x6 // Batch Block MessageType
+	MessageTypeTx              MessageType = 0x1 // Transaction MessageType
+	MessageTypeBlock           MessageType = 0x2 // Block MessageType
+	MessageTypeBlockRequest    MessageType = 0x3 // Block Request MessageType
+	MessageTypeStatus          MessageType = 0x4 // Node Status MessageType
+	MessageTypeStatusRequest   MessageType = 0x5 // Node Status Request MessageType
+	MessageTypeBlocks          MessageType = 0x6 // Batch Block MessageType
+	MessageTypeTxProofRequest  MessageType = 0x7 // Transaction Inclusion Proof Request MessageType
+	MessageTypeTxProofResponse MessageType = 0x8 // Transaction Inclusion Proof Response MessageType
 )
 
 // A RPC is transmitted over Tranports.
@@ -0,0 +1,43 @@
+package network
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pacokleitz/ambula/crypto"
+)
+
+var (
+	GenesisMismatch = errors.New("The peer genesis block does not match the local genesis block.")
+)
+
+// A StatusMessage describes the chain held by a Node.
+// It is sent in a Message of type MessageTypeStatus.
+type StatusMessage struct {
+	Version       uint32      // Version of the Block format used by the Node
+	Height        uint32      // Height of the last Block of the Node chain
+	LastBlockHash crypto.Hash // Hash of the last Block of the Node chain
+	GenesisHash   crypto.Hash // Hash of the genesis Block of the Node chain
+}
+
+// Encode writes the gob encoding of the StatusMessage in the io.Writer w.
+func (msg *StatusMessage) Encode(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(msg)
+}
+
+// Decode reads the gob encoding in io.Reader r in the StatusMessage.
+func (msg *StatusMessage) Decode(r io.Reader) error {
+	return gob.NewDecoder(r).Decode(msg)
+}
+
+// CheckCompatible checks that the peer StatusMessage describes the same chain
+// (same genesis Block) so that the Node can sync with the peer.
+func (msg *StatusMessage) CheckCompatible(peer *StatusMessage) error {
+	if msg.GenesisHash != peer.GenesisHash {
+		return fmt.Errorf("Peer genesis [%s], local genesis [%s]: %w", peer.GenesisHash.String(), msg.GenesisHash.String(), GenesisMismatch)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package network
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pacokleitz/ambula/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusMessageExchange(t *testing.T) {
+	aAddr := NetAddr{Addr: "A", Net: "local"}
+	bAddr := NetAddr{Addr: "B", Net: "local"}
+
+	ltra := NewLocalTransport(aAddr)
+	ltrb := NewLocalTransport(bAddr)
+
+	err := ltra.Connect(ltrb)
+	assert.Nil(t, err)
+
+	// Send the A status to B.
+	statusA := &StatusMessage{
+		Version:       1,
+		Height:        42,
+		LastBlockHash: crypto.Hash{0x42},
+		GenesisHash:   crypto.Hash{0x01},
+	}
+	buf := &bytes.Buffer{}
+	assert.Nil(t, statusA.Encode(buf))
+	assert.Nil(t, ltra.SendMessage(bAddr, buf.Bytes()))
+
+	// Decode the received status and compare it with the sent one.
+	rpc := <-ltrb.Consume()
+	received := new(StatusMessage)
+	assert.Nil(t, received.Decode(rpc.Payload))
+	assert.Equal(t, statusA, received)
+
+	// Check that B accepts a peer with the same genesis.
+	statusB := &StatusMessage{Version: 1, Height: 1, GenesisHash: crypto.Hash{0x01}}
+	assert.Nil(t, statusB.CheckCompatible(received))
+
+	// Check that a genesis mismatch is detected.
+	statusB.GenesisHash = crypto.Hash{0x02}
+	assert.ErrorIs(t, statusB.CheckCompatible(received), GenesisMismatch)
+}
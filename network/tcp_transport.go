@@ -0,0 +1,396 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// TCP_MAX_FRAME_SIZE is the maximum size in bytes of a payload sent over a TCPTransport.
+const TCP_MAX_FRAME_SIZE = 32 << 20
+
+// TCP_MAX_HANDSHAKE_SIZE is the maximum size in bytes of the handshake frame carrying
+// the dialing peer listen address, so an unauthenticated connection can not make
+// the TCPTransport allocate a full frame.
+const TCP_MAX_HANDSHAKE_SIZE = 256
+
+// TCP_HANDSHAKE_TIMEOUT is the time allowed to a new connection to send its handshake.
+const TCP_HANDSHAKE_TIMEOUT = 5 * time.Second
+
+var (
+	FrameTooLarge   = errors.New("The frame size exceeds the maximum frame size.")
+	TransportClosed = errors.New("The transport is closed.")
+)
+
+// A tcpPeer is a connection to a peer TCPTransport.
+type tcpPeer struct {
+	addr   NetAddr    // Listen address of the peer
+	conn   net.Conn   // Connection to the peer
+	dialed bool       // Whether the connection was dialed to the peer listen address
+	lock   sync.Mutex // Lock serializing the frames written to the connection
+}
+
+// TCPTransport is a Transport implementation exchanging length-prefixed
+// frames over TCP connections. The first frame sent on a new connection is
+// a handshake carrying the listen address of the dialing TCPTransport, so
+// both ends can reply to each other over the same connection.
+// The handshake address is not authenticated, so an accepted connection never
+// replaces a connected peer claiming the same address: it is only read from.
+type TCPTransport struct {
+	addr     NetAddr
+	listener net.Listener
+	peers    map[string]*tcpPeer
+	conns    map[net.Conn]struct{}
+	rpcCh    chan RPC
+	quitCh   chan struct{}
+	wg       sync.WaitGroup
+	lock     sync.RWMutex
+}
+
+// NewTCPTransport returns a TCPTransport listening on the TCP address listenAddr.
+// Using port 0 lets the system pick a free port, Addr returns the actual address.
+func NewTCPTransport(listenAddr string) (*TCPTransport, error) {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := &TCPTransport{
+		addr:     NetAddr{Addr: listener.Addr().String(), Net: "tcp"},
+		listener: listener,
+		peers:    make(map[string]*tcpPeer),
+		conns:    make(map[net.Conn]struct{}),
+		rpcCh:    make(chan RPC, RPC_CHAN_SIZE),
+		quitCh:   make(chan struct{}),
+	}
+
+	tr.wg.Add(1)
+	go tr.acceptLoop()
+
+	return tr, nil
+}
+
+// Consume returns the TCPTransport RPC receive channel.
+// The channel is closed when the TCPTransport is closed.
+func (tr *TCPTransport) Consume() <-chan RPC {
+	return tr.rpcCh
+}
+
+// Connect dials the peer Transport listen address and keeps the connection
+// to send and receive RPCs. A connection already dialed to the peer is reused.
+func (tr *TCPTransport) Connect(peerTr Transport) error {
+	tr.lock.RLock()
+	existing, ok := tr.peers[peerTr.Addr().String()]
+	tr.lock.RUnlock()
+	if ok && existing.dialed {
+		return nil
+	}
+
+	conn, err := net.Dial("tcp", peerTr.Addr().String())
+	if err != nil {
+		return err
+	}
+
+	if err := writeFrame(conn, []byte(tr.addr.Addr)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	peer := &tcpPeer{
+		addr:   NetAddr{Addr: peerTr.Addr().String(), Net: "tcp"},
+		conn:   conn,
+		dialed: true,
+	}
+
+	if err := tr.addPeer(peer); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("Transport %s on %s network: %w", tr.addr.String(), tr.addr.Network(), err)
+	}
+
+	return nil
+}
+
+// SendMessage sends a payload to a connected peer in a frame.
+// On success the frame is only written to the connection, the peer may never read it.
+func (tr *TCPTransport) SendMessage(to net.Addr, payload []byte) error {
+	return tr.sendMessage(context.Background(), to, payload)
+}
+
+// sendMessage sends a payload to a connected peer in a frame and gives up
+// when the context is cancelled or its deadline expires before the frame is written.
+func (tr *TCPTransport) sendMessage(ctx context.Context, to net.Addr, payload []byte) error {
+	if tr.addr.String() == to.String() {
+		return nil
+	}
+
+	tr.lock.RLock()
+	peer, ok := tr.peers[to.String()]
+	tr.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("Transport %s on %s network could not find peer %s.", tr.Addr().String(), tr.Addr().Network(), to)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+
+	deadline, _ := ctx.Deadline()
+	if err := peer.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+
+	// Interrupt a stalled write on cancellation by moving the write deadline to now.
+	// The watcher is waited for before the peer lock is released so it can not
+	// shorten the deadline of the next frame.
+	if done := ctx.Done(); done != nil {
+		stopCh := make(chan struct{})
+		watcherCh := make(chan struct{})
+		go func() {
+			defer close(watcherCh)
+			select {
+			case <-done:
+				_ = peer.conn.SetWriteDeadline(time.Now())
+			case <-stopCh:
+			}
+		}()
+		defer func() {
+			close(stopCh)
+			<-watcherCh
+		}()
+	}
+
+	if err := writeFrame(peer.conn, payload); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Broadcast sends a payload in a frame to all the connected peers concurrently.
+// A peer that can not be reached before the context is done does not prevent
+// the delivery to the other peers, the failures are gathered in a BroadcastError.
+func (tr *TCPTransport) Broadcast(ctx context.Context, payload []byte) error {
+	tr.lock.RLock()
+	peers := make([]net.Addr, 0, len(tr.peers))
+	for _, peer := range tr.peers {
+		peers = append(peers, peer.addr)
+	}
+	tr.lock.RUnlock()
+
+	errs := make([]error, len(peers))
+	wg := sync.WaitGroup{}
+	for i, addr := range peers {
+		wg.Add(1)
+		go func(i int, addr net.Addr) {
+			defer wg.Done()
+			errs[i] = tr.sendMessage(ctx, addr, payload)
+		}(i, addr)
+	}
+	wg.Wait()
+
+	broadcastErr := &BroadcastError{Errors: make(map[net.Addr]error)}
+	for i, err := range errs {
+		if err != nil {
+			broadcastErr.Errors[peers[i]] = err
+		}
+	}
+
+	if len(broadcastErr.Errors) != 0 {
+		return broadcastErr
+	}
+
+	return nil
+}
+
+// Addr returns the TCPTransport listen NetAddr.
+func (tr *TCPTransport) Addr() net.Addr {
+	return tr.addr
+}
+
+// Close stops listening, closes all the peer connections and then
+// closes the RPC receive channel.
+func (tr *TCPTransport) Close() error {
+	tr.lock.Lock()
+	select {
+	case <-tr.quitCh:
+		tr.lock.Unlock()
+		return nil
+	default:
+	}
+	close(tr.quitCh)
+
+	err := tr.listener.Close()
+	for conn := range tr.conns {
+		_ = conn.Close()
+	}
+	tr.lock.Unlock()
+
+	tr.wg.Wait()
+	close(tr.rpcCh)
+
+	return err
+}
+
+// acceptLoop accepts the incoming connections until the listener is closed.
+func (tr *TCPTransport) acceptLoop() {
+	defer tr.wg.Done()
+
+	for {
+		conn, err := tr.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		tr.lock.Lock()
+		tr.conns[conn] = struct{}{}
+		tr.lock.Unlock()
+
+		tr.wg.Add(1)
+		go tr.handshake(conn)
+	}
+}
+
+// handshake reads the listen address of the dialing peer on an accepted
+// connection and registers the peer.
+func (tr *TCPTransport) handshake(conn net.Conn) {
+	defer tr.wg.Done()
+
+	peer := &tcpPeer{conn: conn}
+
+	if err := conn.SetReadDeadline(time.Now().Add(TCP_HANDSHAKE_TIMEOUT)); err != nil {
+		tr.removePeer(peer)
+		return
+	}
+
+	addr, err := readFrame(conn, TCP_MAX_HANDSHAKE_SIZE)
+	if err != nil {
+		tr.removePeer(peer)
+		return
+	}
+	peer.addr = NetAddr{Addr: string(addr), Net: "tcp"}
+
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		tr.removePeer(peer)
+		return
+	}
+
+	if err := tr.addPeer(peer); err != nil {
+		tr.removePeer(peer)
+	}
+}
+
+// addPeer spawns the read loop of the peer and registers it as the peer used to
+// send to its address. An accepted peer is only registered if no peer uses its
+// address since the address is only claimed by the remote end of the connection.
+// A dialed peer replaces an accepted one, which keeps being read from, and closes
+// the connection of a previously dialed one.
+func (tr *TCPTransport) addPeer(peer *tcpPeer) error {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+
+	select {
+	case <-tr.quitCh:
+		return TransportClosed
+	default:
+	}
+
+	existing, ok := tr.peers[peer.addr.String()]
+	switch {
+	case !ok:
+		tr.peers[peer.addr.String()] = peer
+	case peer.dialed:
+		if existing.dialed {
+			_ = existing.conn.Close()
+		}
+		tr.peers[peer.addr.String()] = peer
+	}
+	tr.conns[peer.conn] = struct{}{}
+
+	tr.wg.Add(1)
+	go tr.readLoop(peer)
+
+	return nil
+}
+
+// readLoop passes the frames read from the peer connection to the RPC
+// receive channel until the connection is closed.
+func (tr *TCPTransport) readLoop(peer *tcpPeer) {
+	defer tr.wg.Done()
+	defer tr.removePeer(peer)
+
+	for {
+		payload, err := readFrame(peer.conn, TCP_MAX_FRAME_SIZE)
+		if err != nil {
+			return
+		}
+
+		rpc := RPC{
+			From:    peer.addr,
+			Payload: bytes.NewReader(payload),
+		}
+
+		select {
+		case tr.rpcCh <- rpc:
+		case <-tr.quitCh:
+			return
+		}
+	}
+}
+
+// removePeer closes the peer connection and unregisters it if it is still current.
+func (tr *TCPTransport) removePeer(peer *tcpPeer) {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+
+	_ = peer.conn.Close()
+	delete(tr.conns, peer.conn)
+	if tr.peers[peer.addr.String()] == peer {
+		delete(tr.peers, peer.addr.String())
+	}
+}
+
+// writeFrame writes the payload to w prefixed with its length.
+func writeFrame(w io.Writer, payload []byte) error {
+	if len(payload) > TCP_MAX_FRAME_SIZE {
+		return FrameTooLarge
+	}
+
+	frame := make([]byte, 4, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// readFrame reads a length-prefixed payload of at most maxSize bytes from r.
+func readFrame(r io.Reader, maxSize uint32) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(size[:])
+	if length > maxSize {
+		return nil, FrameTooLarge
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,222 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPTransportRoundTrip(t *testing.T) {
+	tra, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer tra.Close()
+
+	trb, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer trb.Close()
+
+	assert.Nil(t, tra.Connect(trb))
+
+	// Send a request from A to B.
+	request := []byte("sign request")
+	assert.Nil(t, tra.SendMessage(trb.Addr(), request))
+
+	rpc := receiveRPC(t, trb)
+	assert.Equal(t, tra.Addr(), rpc.From)
+	b, err := io.ReadAll(rpc.Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, request, b)
+
+	// Reply from B to A over the connection opened by A.
+	response := []byte("sign response")
+	assert.Nil(t, trb.SendMessage(rpc.From, response))
+
+	rpc = receiveRPC(t, tra)
+	assert.Equal(t, trb.Addr(), rpc.From)
+	b, err = io.ReadAll(rpc.Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, response, b)
+}
+
+func TestTCPTransportBroadcast(t *testing.T) {
+	tra, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer tra.Close()
+
+	trb, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer trb.Close()
+
+	trc, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer trc.Close()
+
+	assert.Nil(t, tra.Connect(trb))
+	assert.Nil(t, tra.Connect(trc))
+
+	msg := []byte("hello ambula")
+	assert.Nil(t, tra.Broadcast(context.Background(), msg))
+
+	for _, tr := range []*TCPTransport{trb, trc} {
+		b, err := io.ReadAll(receiveRPC(t, tr).Payload)
+		assert.Nil(t, err)
+		assert.Equal(t, msg, b)
+	}
+}
+
+func TestTCPTransportClose(t *testing.T) {
+	tra, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+
+	trb, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer trb.Close()
+
+	assert.Nil(t, tra.Connect(trb))
+
+	// Close A and check that its receive channel is closed and it can not send anymore.
+	assert.Nil(t, tra.Close())
+	_, ok := <-tra.Consume()
+	assert.False(t, ok)
+	assert.NotNil(t, tra.SendMessage(trb.Addr(), []byte("hello ambula")))
+	assert.NotNil(t, tra.Connect(trb))
+}
+
+func TestTCPTransportImpostor(t *testing.T) {
+	tra, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer tra.Close()
+
+	trb, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer trb.Close()
+
+	// An impostor dials A and claims the B listen address before A connects to B.
+	early := dialImpostor(t, tra, trb.Addr())
+	defer early.Close()
+	assert.Nil(t, tra.Connect(trb))
+
+	// An impostor dials A and claims the B listen address after A connected to B.
+	late := dialImpostor(t, tra, trb.Addr())
+	defer late.Close()
+
+	// Check that A sends to B and not to the impostors.
+	msg := []byte("hello ambula")
+	assert.Nil(t, tra.SendMessage(trb.Addr(), msg))
+	b, err := io.ReadAll(receiveRPC(t, trb).Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, b)
+	for _, conn := range []net.Conn{early, late} {
+		assert.Nil(t, conn.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+		_, err := conn.Read(make([]byte, 1))
+		assert.True(t, errors.Is(err, os.ErrDeadlineExceeded))
+	}
+}
+
+func TestTCPTransportMutualConnect(t *testing.T) {
+	tra, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer tra.Close()
+
+	trb, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer trb.Close()
+
+	// Connect both ends to each other like the LocalTransports.
+	assert.Nil(t, tra.Connect(trb))
+	assert.Nil(t, trb.Connect(tra))
+
+	// Check that the messages are delivered in both directions.
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, tra.SendMessage(trb.Addr(), []byte("from A")))
+		rpc := receiveRPC(t, trb)
+		assert.Equal(t, tra.Addr(), rpc.From)
+
+		assert.Nil(t, trb.SendMessage(tra.Addr(), []byte("from B")))
+		rpc = receiveRPC(t, tra)
+		assert.Equal(t, trb.Addr(), rpc.From)
+	}
+}
+
+// dialImpostor dials the TCPTransport claiming the addr in its handshake and
+// returns once a frame sent on the connection was received by the TCPTransport.
+func dialImpostor(t *testing.T, tr *TCPTransport, addr net.Addr) net.Conn {
+	conn, err := net.Dial("tcp", tr.Addr().String())
+	assert.Nil(t, err)
+	assert.Nil(t, writeFrame(conn, []byte(addr.String())))
+	assert.Nil(t, writeFrame(conn, []byte("impostor")))
+	receiveRPC(t, tr)
+
+	return conn
+}
+
+func TestTCPTransportHandshakeSize(t *testing.T) {
+	tra, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer tra.Close()
+
+	// Check that a handshake frame larger than TCP_MAX_HANDSHAKE_SIZE closes the connection.
+	conn, err := net.Dial("tcp", tra.Addr().String())
+	assert.Nil(t, err)
+	defer conn.Close()
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], TCP_MAX_FRAME_SIZE)
+	_, err = conn.Write(size[:])
+	assert.Nil(t, err)
+	assertClosedByPeer(t, conn)
+}
+
+func TestTCPTransportSendCancel(t *testing.T) {
+	tra, err := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer tra.Close()
+
+	// A peer that accepts the connection but never reads it.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			<-doneCh
+			_ = conn.Close()
+		}
+	}()
+	stalled := NewLocalTransport(NetAddr{Addr: listener.Addr().String(), Net: "tcp"})
+	assert.Nil(t, tra.Connect(stalled))
+
+	// Check that cancelling a context without deadline interrupts the stalled write.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	err = tra.sendMessage(ctx, stalled.Addr(), make([]byte, TCP_MAX_FRAME_SIZE))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// assertClosedByPeer checks that the remote end closes the connection before a timeout.
+func assertClosedByPeer(t *testing.T, conn net.Conn) {
+	assert.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err := conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+// receiveRPC returns the next RPC received by the TCPTransport or fails the test after a timeout.
+func receiveRPC(t *testing.T, tr *TCPTransport) RPC {
+	select {
+	case rpc := <-tr.Consume():
+		return rpc
+	case <-time.After(5 * time.Second):
+		t.Fatal("no RPC received")
+		return RPC{}
+	}
+}
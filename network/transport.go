@@ -1,13 +1,39 @@
 package network
 
-import "net"
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
 
 // The Transport interface exposes the methods needed for
 // communication between peers.
+//
+// A nil error from SendMessage or Broadcast only means that the payload
+// was handed over to the Transport, not that the peer received or processed it.
+// Flows expecting an answer must wait for the peer response instead.
 type Transport interface {
 	Consume() <-chan RPC
 	Connect(Transport) error
 	SendMessage(net.Addr, []byte) error
-	Broadcast([]byte) error
+	Broadcast(context.Context, []byte) error
 	Addr() net.Addr
 }
+
+// A BroadcastError gathers the errors of the peers a Broadcast failed to reach.
+type BroadcastError struct {
+	Errors map[net.Addr]error // Errors indexed by the address of the unreached peer
+}
+
+// Error returns the list of unreached peers with their error sorted by address.
+func (e *BroadcastError) Error() string {
+	failures := make([]string, 0, len(e.Errors))
+	for addr, err := range e.Errors {
+		failures = append(failures, fmt.Sprintf("%s: %s", addr.String(), err.Error()))
+	}
+	sort.Strings(failures)
+
+	return fmt.Sprintf("Broadcast failed to reach %d peer(s) [%s].", len(e.Errors), strings.Join(failures, ", "))
+}
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/pacokleitz/ambula/core"
+	"github.com/pacokleitz/ambula/crypto"
+)
+
+// A TxProofRequestMessage asks a peer for the proof that a Transaction is included in a Block.
+// It is sent in a Message of type MessageTypeTxProofRequest.
+type TxProofRequestMessage struct {
+	BlockHash crypto.Hash // HeaderHash of the Block including the Transaction
+	TxHash    crypto.Hash // Hash of the Transaction
+}
+
+// Encode writes the gob encoding of the TxProofRequestMessage in the io.Writer w.
+func (msg *TxProofRequestMessage) Encode(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(msg)
+}
+
+// Decode reads the gob encoding in io.Reader r in the TxProofRequestMessage.
+func (msg *TxProofRequestMessage) Decode(r io.Reader) error {
+	return gob.NewDecoder(r).Decode(msg)
+}
+
+// A TxProofResponseMessage carries a Transaction and the MerkleProof of its inclusion in a Block.
+// It is sent in a Message of type MessageTypeTxProofResponse.
+type TxProofResponseMessage struct {
+	BlockHash crypto.Hash       // HeaderHash of the Block including the Transaction
+	Tx        *core.Transaction // Transaction included in the Block
+	Proof     *core.MerkleProof // Merkle branch from the Transaction leaf to the Block DataHash
+}
+
+// NewTxProofResponse builds the TxProofResponseMessage answering the request
+// from the Blocks of the Storage.
+func NewTxProofResponse(storage core.Storage, req *TxProofRequestMessage) (*TxProofResponseMessage, error) {
+	b, err := storage.GetBlock(req.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, proof, err := b.InclusionProof(req.TxHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TxProofResponseMessage{
+		BlockHash: req.BlockHash,
+		Tx:        tx,
+		Proof:     proof,
+	}, nil
+}
+
+// Encode writes the gob encoding of the TxProofResponseMessage in the io.Writer w.
+func (msg *TxProofResponseMessage) Encode(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(msg)
+}
+
+// Decode reads the gob encoding in io.Reader r in the TxProofResponseMessage.
+func (msg *TxProofResponseMessage) Decode(r io.Reader) error {
+	return gob.NewDecoder(r).Decode(msg)
+}
+
+// Verify checks that the TxProofResponseMessage answers the request and proves the requested
+// Transaction inclusion in the Block described by the Header, which is all a light client needs to hold.
+func (msg *TxProofResponseMessage) Verify(req *TxProofRequestMessage, header *core.Header) error {
+	if header == nil {
+		return core.BlockMissingHeader
+	}
+
+	headerHash := core.BlockHasher{}.Hash(header)
+	if headerHash != req.BlockHash || msg.BlockHash != req.BlockHash {
+		return fmt.Errorf("Proof block [%s], requested block [%s], header [%s]: %w", msg.BlockHash.String(), req.BlockHash.String(), headerHash.String(), core.MerkleProofMismatch)
+	}
+
+	if msg.Tx == nil || msg.Proof == nil {
+		return fmt.Errorf("Proof block [%s] is incomplete: %w", msg.BlockHash.String(), core.MerkleProofMismatch)
+	}
+
+	txHash := msg.Tx.Hash(core.TxHasher{})
+	if txHash != req.TxHash {
+		return fmt.Errorf("Proof tx [%s], requested tx [%s]: %w", txHash.String(), req.TxHash.String(), core.MerkleProofMismatch)
+	}
+
+	return core.VerifyInclusion(msg.Tx, msg.Proof, header)
+}
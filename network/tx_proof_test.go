@@ -0,0 +1,81 @@
+package network
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pacokleitz/ambula/core"
+	"github.com/pacokleitz/ambula/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxProofExchange(t *testing.T) {
+	fullAddr := NetAddr{Addr: "full", Net: "local"}
+	lightAddr := NetAddr{Addr: "light", Net: "local"}
+
+	full := NewLocalTransport(fullAddr)
+	light := NewLocalTransport(lightAddr)
+	assert.Nil(t, full.Connect(light))
+	assert.Nil(t, light.Connect(full))
+
+	// Store a signed Block holding several Transactions on the full node.
+	privKey, err := crypto.GeneratePrivateKey()
+	assert.Nil(t, err)
+	b, err := core.NewBlock(&core.Header{Version: core.PROTOCOL_VERSION, Height: 1, Timestamp: time.Now().UnixNano()}, nil)
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		tx := core.NewTransaction([]byte("foo"), crypto.Address{0x42}, 1, 1, uint64(i), 0)
+		assert.Nil(t, tx.Sign(privKey))
+		assert.Nil(t, b.AddTx(tx))
+	}
+	assert.Nil(t, b.Sign(privKey))
+	storage := core.NewMemoryStorage()
+	assert.Nil(t, storage.PutBlock(b))
+
+	// The light client asks for the inclusion proof of the second Transaction.
+	req := &TxProofRequestMessage{
+		BlockHash: b.HeaderHash(core.BlockHasher{}),
+		TxHash:    b.Transactions[1].Hash(core.TxHasher{}),
+	}
+	buf := &bytes.Buffer{}
+	assert.Nil(t, req.Encode(buf))
+	assert.Nil(t, light.SendMessage(fullAddr, buf.Bytes()))
+
+	// The full node answers the request from its Storage.
+	received := new(TxProofRequestMessage)
+	assert.Nil(t, received.Decode((<-full.Consume()).Payload))
+	resp, err := NewTxProofResponse(storage, received)
+	assert.Nil(t, err)
+	buf.Reset()
+	assert.Nil(t, resp.Encode(buf))
+	assert.Nil(t, full.SendMessage(lightAddr, buf.Bytes()))
+
+	// Check that the light client verifies the inclusion holding the Header only.
+	proofMsg := new(TxProofResponseMessage)
+	assert.Nil(t, proofMsg.Decode((<-light.Consume()).Payload))
+	header := *b.Header
+	assert.Nil(t, proofMsg.Verify(req, &header))
+
+	// Check that a valid proof of another Transaction of the Block is rejected.
+	substituted, err := NewTxProofResponse(storage, &TxProofRequestMessage{
+		BlockHash: req.BlockHash,
+		TxHash:    b.Transactions[0].Hash(core.TxHasher{}),
+	})
+	assert.Nil(t, err)
+	assert.ErrorIs(t, substituted.Verify(req, &header), core.MerkleProofMismatch)
+
+	// Check that a forged proof is rejected.
+	proofMsg.Tx.Value = 1000
+	proofMsg.Tx.InvalidateHash()
+	assert.ErrorIs(t, proofMsg.Verify(req, &header), core.MerkleProofMismatch)
+
+	// Check that a proof for another Block is rejected.
+	otherHeader := header
+	otherHeader.Height++
+	assert.ErrorIs(t, resp.Verify(req, &otherHeader), core.MerkleProofMismatch)
+
+	// Check that an unknown Transaction has no proof.
+	_, err = NewTxProofResponse(storage, &TxProofRequestMessage{BlockHash: req.BlockHash})
+	assert.ErrorIs(t, err, core.TxNotInBlock)
+}
@@ -4,17 +4,19 @@ package random
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"math/big"
 )
 
 var (
 	InvalidUpperBound = errors.New("The RandomInt upper bound should be > 0.")
+	InvalidLength     = errors.New("The RandomBytes length should be >= 0.")
 )
 
 // RandomInt returns a random Int64 between [0, upperBound).
 func RandomInt(upperBound int64) (int64, error) {
-	if upperBound < 0 {
+	if upperBound <= 0 {
 		return 0, InvalidUpperBound
 	}
 
@@ -25,3 +27,27 @@ func RandomInt(upperBound int64) (int64, error) {
 
 	return randomValue.Int64(), nil
 }
+
+// RandomBytes returns a slice of n random bytes.
+func RandomBytes(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, InvalidLength
+	}
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// RandomUint64 returns a random Uint64 between [0, MaxUint64].
+func RandomUint64() (uint64, error) {
+	b, err := RandomBytes(8)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint64(b), nil
+}
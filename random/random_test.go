@@ -22,3 +22,53 @@ func TestRandomIntRandomness(t *testing.T) {
 	// No way this fails
 	assert.NotEqual(t, randomVal1, randomVal2)
 }
+
+func TestRandomIntZero(t *testing.T) {
+	_, err := RandomInt(0)
+	assert.Equal(t, err, InvalidUpperBound)
+}
+
+func TestRandomIntBounds(t *testing.T) {
+	// Check that every value stays in [0, upperBound) and that all values are reached.
+	seen := make(map[int64]bool)
+	for i := 0; i < 1000; i++ {
+		randomVal, err := RandomInt(4)
+		assert.Nil(t, err)
+		assert.GreaterOrEqual(t, randomVal, int64(0))
+		assert.Less(t, randomVal, int64(4))
+		seen[randomVal] = true
+	}
+	assert.Len(t, seen, 4)
+}
+
+func TestRandomBytes(t *testing.T) {
+	_, err := RandomBytes(-1)
+	assert.Equal(t, err, InvalidLength)
+
+	empty, err := RandomBytes(0)
+	assert.Nil(t, err)
+	assert.Len(t, empty, 0)
+
+	randomBytes1, err := RandomBytes(32)
+	assert.Nil(t, err)
+	assert.Len(t, randomBytes1, 32)
+
+	randomBytes2, err := RandomBytes(32)
+	assert.Nil(t, err)
+
+	// No way this fails
+	assert.NotEqual(t, randomBytes1, randomBytes2)
+}
+
+func TestRandomUint64Distribution(t *testing.T) {
+	// Check that the high bit is set for roughly half of the values.
+	highBits := 0
+	for i := 0; i < 1000; i++ {
+		randomVal, err := RandomUint64()
+		assert.Nil(t, err)
+		if randomVal > math.MaxUint64/2 {
+			highBits++
+		}
+	}
+	assert.InDelta(t, 500, highBits, 100)
+}